@@ -0,0 +1,84 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"strings"
+	"time"
+)
+
+// WithChangeAnnotations makes every save add a comment such as
+// "# modified 2024-06-01T10:00:00Z by mytool" at the end of each entry line
+// added or changed since the file was last loaded or saved, with the time
+// of the save in UTC and tool as given. A line that already ends with an
+// annotation by tool has its time updated instead of getting a second one,
+// and a comment already on the line is kept before the annotation. Lines
+// that were not changed are never annotated. tool must not contain "#" or
+// line breaks. The time is taken from the clock set with WithClock.
+func WithChangeAnnotations(tool string) Option {
+	return func(h *HostsEdit) {
+		h.annotateTool = tool
+	}
+}
+
+// WithClock sets the function used to get the current time, time.Now by
+// default, so that tests can control the times written by
+// WithChangeAnnotations.
+func WithClock(now func() time.Time) Option {
+	return func(h *HostsEdit) {
+		h.now = now
+	}
+}
+
+// clock returns the current time from the clock set with WithClock.
+func (h *HostsEdit) clock() time.Time {
+	if h.now != nil {
+		return h.now()
+	}
+	return time.Now()
+}
+
+// annotateChanges adds or updates the annotation of WithChangeAnnotations on
+// the active entry lines changed since the last load or save.
+func (h *HostsEdit) annotateChanges() {
+	if h.annotateTool == "" {
+		return
+	}
+	note := "modified " + h.clock().UTC().Format(time.RFC3339) + " by " + h.annotateTool
+	for _, line := range h.Lines {
+		if !line.dirty || !isActive(line) {
+			continue
+		}
+		line.InlineComment = annotate(line.InlineComment, note, h.annotateTool)
+	}
+}
+
+// annotate returns comment with note at its end, in place of an earlier
+// annotation by tool if comment ends with one.
+func annotate(comment, note, tool string) string {
+	if rest, ok := strings.CutSuffix(comment, " by "+tool); ok {
+		// 找到上一次的注释时只更新时间
+		i := strings.LastIndex(rest, "modified ")
+		if i >= 0 && (i == 0 || strings.HasSuffix(rest[:i], "# ")) && !strings.ContainsAny(rest[i+len("modified "):], " \t") {
+			return rest[:i] + note
+		}
+	}
+	if comment == "" {
+		return note
+	}
+	return comment + " # " + note
+}
@@ -0,0 +1,88 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// 测试WithChangeAnnotations选项
+func TestWithChangeAnnotations(t *testing.T) {
+	hostsContent := "127.0.0.1 localhost\n10.0.0.1 a.test # owned by ops\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	hostsEdit, _ := New(filePath, false, WithChangeAnnotations("mytool"), WithClock(func() time.Time { return now }))
+
+	err = hostsEdit.Edit("a.test", "10.0.0.2")
+	if err != nil {
+		t.Fatalf("Edit(a.test, 10.0.0.2) failed with error: %v", err)
+	}
+	err = hostsEdit.Edit("b.test", "10.0.0.3")
+	if err != nil {
+		t.Fatalf("Edit(b.test, 10.0.0.3) failed with error: %v", err)
+	}
+	// 未修改的行不加注释
+	want := "10.0.0.3 b.test # modified 2024-06-01T10:00:00Z by mytool\n" +
+		"127.0.0.1 localhost\n" +
+		"10.0.0.2 a.test # owned by ops # modified 2024-06-01T10:00:00Z by mytool\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+
+	// 再次修改时更新时间，而不是追加新的注释
+	now = now.Add(time.Hour)
+	hostsEdit, _ = New(filePath, false, WithChangeAnnotations("mytool"), WithClock(func() time.Time { return now }))
+	err = hostsEdit.Edit("a.test", "10.0.0.4")
+	if err != nil {
+		t.Fatalf("Edit(a.test, 10.0.0.4) failed with error: %v", err)
+	}
+	want = "10.0.0.3 b.test # modified 2024-06-01T10:00:00Z by mytool\n" +
+		"127.0.0.1 localhost\n" +
+		"10.0.0.4 a.test # owned by ops # modified 2024-06-01T11:00:00Z by mytool\n"
+	contentBytes, _ = os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+}
+
+// 测试annotate函数
+func TestAnnotate(t *testing.T) {
+	note := "modified 2024-06-01T10:00:00Z by mytool"
+	tests := []struct {
+		comment string
+		want    string
+	}{
+		{"", note},
+		{"modified 2020-01-01T00:00:00Z by mytool", note},
+		{"keep # modified 2020-01-01T00:00:00Z by mytool", "keep # " + note},
+		{"modified 2020-01-01T00:00:00Z by other", "modified 2020-01-01T00:00:00Z by other # " + note},
+		{"not modified here by mytool", "not modified here by mytool # " + note},
+	}
+	for _, tt := range tests {
+		if got := annotate(tt.comment, note, "mytool"); got != tt.want {
+			t.Errorf("annotate(%q) = %q; want %q", tt.comment, got, tt.want)
+		}
+	}
+}
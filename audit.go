@@ -0,0 +1,173 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// AuditRecord is one line of the audit log written by WithAuditLog.
+type AuditRecord struct {
+	Time time.Time `json:"time"`
+	// Event is "change" for a host whose mapping a save changed, and "save"
+	// for the save itself, recorded after its changes.
+	Event string `json:"event"`
+	// Operation is the method that saved, such as "Edit" or "Save".
+	Operation string `json:"operation"`
+	// Change is the kind of change, as returned by ChangeOp.String, for a
+	// "change" record.
+	Change string `json:"change,omitempty"`
+	// Host and NewIP are the host and IP address the operation was called
+	// with for a "save" record, if any, and OldIP is empty.
+	Host  string `json:"host,omitempty"`
+	OldIP string `json:"old_ip,omitempty"`
+	NewIP string `json:"new_ip,omitempty"`
+	File  string `json:"file,omitempty"`
+	// SHA256 is the hex-encoded SHA-256 hash of the content of the file
+	// after the save, including the lines an appending save left as they
+	// were.
+	SHA256 string `json:"sha256,omitempty"`
+	// Error is set, on every record of the save, if the save failed. The
+	// changes of a failed save are then not in the file.
+	Error string `json:"error,omitempty"`
+}
+
+// WithAuditLog makes every save write an AuditRecord to w, as one JSON
+// object per line, for each host whose effective mapping, the first active
+// entry for it, has changed since the file was loaded or last saved, and
+// then one for the save. The records are written once the write of the file
+// has ended, also when it failed. Use OpenAuditLog to open an audit file.
+//
+// The changes of a failed save are recorded with the error and recorded
+// again by the next successful save. The records of SaveDebounced are
+// written when the background write ends. Errors writing to w are logged to
+// the logger of WithLogger, if any.
+func WithAuditLog(w io.Writer) Option {
+	return func(h *HostsEdit) {
+		h.auditLog = &auditLog{w: w}
+	}
+}
+
+// OpenAuditLog opens the file at path for WithAuditLog, creating it if
+// needed. The file is opened for appending only and, if created, is only
+// readable and writable by its owner. The caller must close it.
+func OpenAuditLog(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+}
+
+// auditLog is the writer of WithAuditLog and the mapping of the last save
+// it recorded, which the changes of the next save are based on.
+type auditLog struct {
+	mu    sync.Mutex // guards all fields, also used by SaveDebounced
+	w     io.Writer
+	ips   map[string]string
+	order []string
+}
+
+// resetAudit makes the current mapping the base of the next change records.
+func (h *HostsEdit) resetAudit() {
+	if a := h.auditLog; a != nil {
+		ips, order := h.effective()
+		a.mu.Lock()
+		a.ips, a.order = ips, order
+		a.mu.Unlock()
+	}
+}
+
+// audit writes the records of a save by op that has just ended with err.
+func (h *HostsEdit) audit(op operation, err error) {
+	if h.auditLog == nil {
+		return
+	}
+	var content []byte
+	if err == nil {
+		content = h.render()
+		// 追加时原有的行没有重写，文件内容可能和渲染结果不同
+		if h.lastWrite == WriteAppend {
+			if b, rerr := os.ReadFile(h.TargetPath()); rerr == nil {
+				content = b
+			}
+		}
+	}
+	h.auditWriter(op, content)(err)
+}
+
+// auditWriter returns the function that writes the records of a save by op
+// of the current lines as content, once the save has ended with err. It may
+// be called on another goroutine. The changes are recorded against the
+// last successful save recorded when it is called, so the changes of a
+// failed save are recorded again by the next one.
+func (h *HostsEdit) auditWriter(op operation, content []byte) func(err error) {
+	a, logger, clock := h.auditLog, h.logger, h.clock
+	ips, order := h.effective()
+	sum := sha256.Sum256(content)
+	save := AuditRecord{
+		Event:     "save",
+		Operation: op.name,
+		Host:      op.host,
+		NewIP:     op.ip,
+		File:      h.FilePath,
+		SHA256:    hex.EncodeToString(sum[:]),
+	}
+
+	return func(err error) {
+		now := clock()
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		d := diffEffective(a.ips, a.order, ips, order)
+		var records []AuditRecord
+		change := func(c ChangeOp, host, oldIP, newIP string) {
+			r := save
+			r.Event, r.Change, r.Host, r.OldIP, r.NewIP = "change", c.String(), host, oldIP, newIP
+			records = append(records, r)
+		}
+		for _, e := range d.Removed {
+			change(OpDelete, e.Host, e.IP, "")
+		}
+		for _, e := range d.Added {
+			change(OpAdd, e.Host, "", e.IP)
+		}
+		for _, c := range d.Changed {
+			change(OpUpdate, c.Host, c.OldIP, c.NewIP)
+		}
+		records = append(records, save)
+		if err == nil {
+			a.ips, a.order = ips, order
+		}
+
+		enc := json.NewEncoder(a.w)
+		for _, r := range records {
+			r.Time = now
+			if err != nil {
+				r.SHA256, r.Error = "", err.Error()
+			}
+			if werr := enc.Encode(r); werr != nil {
+				if logger != nil {
+					logger.Error("writing audit log failed", "file", r.File, "error", werr)
+				}
+				return
+			}
+		}
+	}
+}
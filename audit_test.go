@@ -0,0 +1,161 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// 测试WithAuditLog选项
+func TestWithAuditLog(t *testing.T) {
+	hostsContent := "10.0.0.1 a.test\n10.0.0.2 b.test\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	now := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	var log bytes.Buffer
+	hostsEdit, _ := New(filePath, false, WithAuditLog(&log), WithClock(func() time.Time { return now }))
+
+	err = hostsEdit.Edit("a.test", "10.0.0.3")
+	if err != nil {
+		t.Fatalf("Edit(a.test, 10.0.0.3) failed with error: %v", err)
+	}
+	err = hostsEdit.Delete("b.test")
+	if err != nil {
+		t.Fatalf("Delete(b.test) failed with error: %v", err)
+	}
+	contentBytes, _ := os.ReadFile(filePath)
+	sum := sha256.Sum256(contentBytes)
+	hash := hex.EncodeToString(sum[:])
+
+	// 保存失败时也记录，且变更在下次成功保存时再次记录
+	hostsEdit.FilePath = filepath.Join(filePath+".missing", "hosts")
+	err = hostsEdit.Edit("c.test", "10.0.0.4")
+	if err == nil {
+		t.Fatalf("Edit() into a missing directory succeeded")
+	}
+
+	records := readAuditLog(t, &log)
+	want := []AuditRecord{
+		{Event: "change", Operation: "Edit", Change: "update", Host: "a.test", OldIP: "10.0.0.1", NewIP: "10.0.0.3"},
+		{Event: "save", Operation: "Edit", Host: "a.test", NewIP: "10.0.0.3"},
+		{Event: "change", Operation: "Delete", Change: "delete", Host: "b.test", OldIP: "10.0.0.2"},
+		{Event: "save", Operation: "Delete", Host: "b.test"},
+		{Event: "change", Operation: "Edit", Change: "add", Host: "c.test", NewIP: "10.0.0.4"},
+		{Event: "save", Operation: "Edit", Host: "c.test", NewIP: "10.0.0.4"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("audit log has %d records; want %d:\n%s", len(records), len(want), log.String())
+	}
+	for i, r := range records {
+		if !r.Time.Equal(now) {
+			t.Errorf("record %d: Time = %v; want %v", i, r.Time, now)
+		}
+		if i < 4 && (r.Error != "" || r.File != filePath || r.SHA256 == "") {
+			t.Errorf("record %d: Error = %q, File = %q, SHA256 = %q", i, r.Error, r.File, r.SHA256)
+		}
+		if i >= 4 && (r.Error == "" || r.SHA256 != "") {
+			t.Errorf("record %d of the failed save: Error = %q, SHA256 = %q", i, r.Error, r.SHA256)
+		}
+		r.Time, r.File, r.SHA256, r.Error = time.Time{}, "", "", ""
+		if r != want[i] {
+			t.Errorf("record %d = %+v; want %+v", i, r, want[i])
+		}
+	}
+	if records[3].SHA256 != hash {
+		t.Errorf("SHA256 = %s; want %s, the hash of the saved file", records[3].SHA256, hash)
+	}
+}
+
+// 测试追加写入时记录的哈希是文件的实际内容
+func TestAuditLogAppendHash(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1   localhost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	var log bytes.Buffer
+	hostsEdit, _ := New(filePath, false, WithAuditLog(&log), WithInsertPolicy(InsertAppend))
+	err = hostsEdit.Edit("a.test", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Edit(a.test, 10.0.0.1) failed with error: %v", err)
+	}
+	if s := hostsEdit.LastWriteStrategy(); s != WriteAppend {
+		t.Fatalf("LastWriteStrategy() = %v; want %v", s, WriteAppend)
+	}
+
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != "127.0.0.1   localhost\n10.0.0.1 a.test\n" {
+		t.Fatalf("saved content = %q; want the original line kept", contentBytes)
+	}
+	sum := sha256.Sum256(contentBytes)
+	records := readAuditLog(t, &log)
+	if len(records) == 0 {
+		t.Fatalf("audit log is empty")
+	}
+	if hash := hex.EncodeToString(sum[:]); records[len(records)-1].SHA256 != hash {
+		t.Errorf("SHA256 = %s; want %s, the hash of the saved file", records[len(records)-1].SHA256, hash)
+	}
+}
+
+// readAuditLog decodes the records of an audit log.
+func readAuditLog(t *testing.T, log *bytes.Buffer) []AuditRecord {
+	var records []AuditRecord
+	scanner := bufio.NewScanner(bytes.NewReader(log.Bytes()))
+	for scanner.Scan() {
+		var r AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("audit log line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+// 测试OpenAuditLog函数
+func TestOpenAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	for i := 0; i < 2; i++ {
+		file, err := OpenAuditLog(path)
+		if err != nil {
+			t.Fatalf("OpenAuditLog() error = %v", err)
+		}
+		file.WriteString("line\n")
+		file.Close()
+	}
+
+	contentBytes, _ := os.ReadFile(path)
+	if string(contentBytes) != "line\nline\n" {
+		t.Errorf("audit file content = %q; want two appended lines", contentBytes)
+	}
+	info, _ := os.Stat(path)
+	if perm := info.Mode().Perm(); perm&0o077 != 0 {
+		t.Errorf("audit file permissions = %v; want no access for others", perm)
+	}
+}
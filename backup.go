@@ -0,0 +1,77 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+package hostedit
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// BackupTo copies the current content of the file at FilePath to destPath,
+// with the same permissions. It reads the file on disk, not the in-memory
+// lines, and never writes to FilePath. For an instance created with
+// NewWithStore, the content is loaded from the store and destPath is created
+// with mode 0644.
+func (h *HostsEdit) BackupTo(destPath string) error {
+	if h.store != nil {
+		rc, err := h.store.Load()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		content, err := io.ReadAll(rc)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(destPath, content, 0o644)
+	}
+	if h.FilePath == "" {
+		return errors.New("no file path to back up")
+	}
+
+	info, err := os.Stat(h.FilePath)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(h.FilePath)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(destPath, content, info.Mode().Perm())
+}
+
+// RestoreFrom replaces the content of the hosts file with the file at
+// srcPath, such as a backup made by BackupTo, and saves it.
+// The file is parsed the same way New does, and h keeps its options.
+func (h *HostsEdit) RestoreFrom(srcPath string) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	file, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = h.load(file)
+	if err != nil {
+		return err
+	}
+
+	return h.saveToFile(operation{name: "RestoreFrom"})
+}
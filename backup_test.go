@@ -0,0 +1,95 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+package hostedit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 测试BackupTo方法
+func TestBackupTo(t *testing.T) {
+	hostsContent := "127.0.0.1   localhost\n# Comment line\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	// 未保存的修改不应进入备份
+	hostsEdit.Lines = append(hostsEdit.Lines, NewLine("10.0.0.1", "api.test"))
+
+	backupPath := filepath.Join(t.TempDir(), "hosts.bak")
+	err = hostsEdit.BackupTo(backupPath)
+	if err != nil {
+		t.Fatalf("BackupTo() error = %v", err)
+	}
+	contentBytes, err := os.ReadFile(backupPath)
+	if err != nil {
+		t.Fatalf("Failed to read backup file: %v", err)
+	}
+	if string(contentBytes) != hostsContent {
+		t.Errorf("backup content = %q; want %q", contentBytes, hostsContent)
+	}
+
+	hostsEdit.FilePath = ""
+	if err := hostsEdit.BackupTo(backupPath); err == nil {
+		t.Errorf("BackupTo() without FilePath should fail")
+	}
+}
+
+// 测试RestoreFrom方法
+func TestRestoreFrom(t *testing.T) {
+	hostsContent := "127.0.0.1 localhost\n# Comment line\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	backupPath := filepath.Join(t.TempDir(), "hosts.bak")
+	err = hostsEdit.BackupTo(backupPath)
+	if err != nil {
+		t.Fatalf("BackupTo() error = %v", err)
+	}
+
+	err = hostsEdit.Edit("api.test", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Edit(api.test, 10.0.0.1) failed with error: %v", err)
+	}
+
+	err = hostsEdit.RestoreFrom(backupPath)
+	if err != nil {
+		t.Fatalf("RestoreFrom() error = %v", err)
+	}
+	if hostsEdit.Exists("api.test") {
+		t.Errorf("RestoreFrom() did not replace the in-memory lines")
+	}
+	contentBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read hosts file: %v", err)
+	}
+	if string(contentBytes) != hostsContent {
+		t.Errorf("restored content = %q; want %q", contentBytes, hostsContent)
+	}
+
+	if err := hostsEdit.RestoreFrom(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Errorf("RestoreFrom() with missing file should fail")
+	}
+}
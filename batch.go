@@ -0,0 +1,166 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// EditOpType is the kind of an EditOp.
+type EditOpType int
+
+const (
+	// OpSet maps Host to IP, as Edit does.
+	OpSet EditOpType = iota
+	// OpRemove removes Host, as Delete does. A host without an entry is
+	// not an error.
+	OpRemove
+	// OpRename replaces Host with NewHost on every entry of Host, keeping
+	// the IP address and the position of the entries.
+	OpRename
+)
+
+// String returns "set", "remove" or "rename".
+func (t EditOpType) String() string {
+	switch t {
+	case OpSet:
+		return "set"
+	case OpRemove:
+		return "remove"
+	case OpRename:
+		return "rename"
+	}
+	return fmt.Sprintf("EditOpType(%d)", int(t))
+}
+
+// EditOp is one operation of EditBatch.
+type EditOp struct {
+	Op      EditOpType
+	Host    string
+	IP      string // for OpSet
+	NewHost string // for OpRename
+}
+
+// EditBatch applies ops in order and saves the file once. Each operation is
+// validated against the lines as the previous operations left them; an
+// invalid one is skipped and the others are still applied. It returns one
+// error per failed operation, which names the index of the operation, and
+// the error of the save, if any, last. The file is written only if an
+// operation changed something. With WithLowercaseHosts, Host and NewHost
+// are lowercased first.
+func (h *HostsEdit) EditBatch(ops []EditOp) []error {
+	if h.readOnly {
+		return []error{ErrReadOnly}
+	}
+	var hosts, ips []string
+	for _, op := range ops {
+		hosts = append(hosts, op.Host, op.NewHost)
+		ips = append(ips, op.IP)
+	}
+	step := h.beginStep(hostOrAddr(hosts, ips))
+
+	var errs []error
+	changed := false
+	for i, op := range ops {
+		if h.lowercaseHosts {
+			op.Host, op.NewHost = lowerASCII(op.Host), lowerASCII(op.NewHost)
+		}
+		prevIP, _ := h.Get(op.Host)
+		ok, err := h.applyEditOp(op)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("op %d (%s %s): %w", i, op.Op, op.Host, err))
+			continue
+		}
+		if ok {
+			switch op.Op {
+			case OpSet:
+				h.recordHistory(OpSet, op.Host, op.IP, prevIP)
+			case OpRemove:
+				h.recordHistory(OpRemove, op.Host, "", prevIP)
+			case OpRename:
+				h.recordHistory(OpRemove, op.Host, "", prevIP)
+				h.recordHistory(OpSet, op.NewHost, prevIP, "")
+			}
+		}
+		changed = changed || ok
+	}
+	if !changed {
+		return errs
+	}
+
+	err := h.saveToFile(operation{name: "EditBatch"})
+	h.endStep(step)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// applyEditOp applies op in memory and reports whether anything changed.
+func (h *HostsEdit) applyEditOp(op EditOp) (bool, error) {
+	if strings.TrimSpace(op.Host) == "" {
+		return false, errors.New("host cannot be empty")
+	}
+	switch op.Op {
+	case OpSet:
+		if !isAddr(op.IP) {
+			return false, fmt.Errorf("invalid ip %q", op.IP)
+		}
+		return h.set(op.Host, op.IP), nil
+	case OpRemove:
+		return h.remove(op.Host), nil
+	case OpRename:
+		if strings.TrimSpace(op.NewHost) == "" {
+			return false, errors.New("new host cannot be empty")
+		}
+		if !h.Exists(op.Host) {
+			return false, fmt.Errorf("host %q has no entry", op.Host)
+		}
+		if !equalFoldASCII(op.Host, op.NewHost) && h.Exists(op.NewHost) {
+			return false, fmt.Errorf("host %q already has an entry", op.NewHost)
+		}
+		return h.rename(op.Host, op.NewHost), nil
+	}
+	return false, fmt.Errorf("unknown operation %s", op.Op)
+}
+
+// rename replaces host with newHost on every active line and reports
+// whether anything changed.
+func (h *HostsEdit) rename(host, newHost string) bool {
+	changed := false
+	for _, line := range h.Lines {
+		if !isActive(line) || !line.HasHost(host) {
+			continue
+		}
+		hosts := make([]string, len(line.hosts))
+		for i, v := range line.hosts {
+			if equalFoldASCII(v, host) {
+				v = newHost
+			}
+			hosts[i] = v
+		}
+		if equalHosts(hosts, line.hosts) {
+			continue
+		}
+		line.setHosts(hosts)
+		line.touch()
+		changed = true
+	}
+	return changed
+}
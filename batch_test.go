@@ -0,0 +1,67 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 测试EditBatch方法
+func TestEditBatch(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n10.0.0.1 old.test web.test\n10.0.0.2 gone.test\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	errs := hostsEdit.EditBatch([]EditOp{
+		{Op: OpSet, Host: "api.test", IP: "10.0.0.3"},
+		{Op: OpSet, Host: "bad.test", IP: "not-an-ip"},
+		{Op: OpRemove, Host: "gone.test"},
+		{Op: OpRename, Host: "old.test", NewHost: "new.test"},
+		{Op: OpRename, Host: "missing.test", NewHost: "other.test"},
+		{Op: OpRename, Host: "web.test", NewHost: "localhost"},
+		{Op: OpRemove, Host: "missing.test"},
+	})
+	if len(errs) != 3 {
+		t.Fatalf("EditBatch() errors = %v; want 3 errors", errs)
+	}
+	if !strings.HasPrefix(errs[0].Error(), "op 1 (set bad.test)") {
+		t.Errorf("EditBatch() error = %v; want op 1 first", errs[0])
+	}
+
+	want := "10.0.0.3 api.test\n127.0.0.1 localhost\n10.0.0.1 new.test web.test\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("EditBatch() wrote %q; want %q", contentBytes, want)
+	}
+
+	// 没有任何修改时不写文件
+	past := time.Now().Add(-time.Hour)
+	os.Chtimes(filePath, past, past)
+	errs = hostsEdit.EditBatch([]EditOp{{Op: OpSet, Host: "api.test", IP: "10.0.0.3"}, {Op: OpRemove, Host: "gone.test"}})
+	if len(errs) != 0 {
+		t.Errorf("EditBatch() errors = %v; want none", errs)
+	}
+	if info, _ := os.Stat(filePath); !info.ModTime().Equal(past) {
+		t.Errorf("EditBatch() wrote the file without changes")
+	}
+}
@@ -0,0 +1,145 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// largeHostsContent 生成一个类似拦截列表的大hosts文件，每行一个不同的IP
+func largeHostsContent(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "192.168.%d.%d host%d.blocklist.example\n", i/256%256, i%256, i)
+	}
+	return b.String()
+}
+
+func benchmarkEditNewHost(b *testing.B, policy InsertPolicy) {
+	filePath, err := createTestHostsFile(largeHostsContent(100000))
+	if err != nil {
+		b.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, err := New(filePath, false, WithInsertPolicy(policy))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ip := fmt.Sprintf("10.%d.%d.%d", i>>16&255, i>>8&255, i&255)
+		err = hostsEdit.Edit(fmt.Sprintf("new%d.test", i), ip)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// 比较追加写入与整体重写
+func BenchmarkEditAppend(b *testing.B) {
+	benchmarkEditNewHost(b, InsertAppend)
+}
+
+func BenchmarkEditRewrite(b *testing.B) {
+	benchmarkEditNewHost(b, InsertTop)
+}
+
+// 加载大文件时的内存分配
+func BenchmarkNew(b *testing.B) {
+	var content strings.Builder
+	for i := 0; i < 25000; i++ {
+		fmt.Fprintf(&content, "0.0.0.0 ads%d.example.com\n", i)
+		fmt.Fprintf(&content, "192.168.%d.%d host%d.lan host%d www.host%d.lan\n", i/256%256, i%256, i, i, i)
+	}
+	filePath, err := createTestHostsFile(content.String())
+	if err != nil {
+		b.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := New(filePath, false)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// 在十万行的文件中搜索
+func BenchmarkSearch(b *testing.B) {
+	hostsEdit, err := NewFromReader(strings.NewReader(largeHostsContent(100000)), false)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		hostsEdit.Search("Host4242")
+	}
+}
+
+func benchmarkSave(b *testing.B, sync bool) {
+	filePath, err := createTestHostsFile(largeHostsContent(100))
+	if err != nil {
+		b.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, err := New(filePath, false, WithSync(sync))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err = hostsEdit.Save()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// 比较同步到磁盘的开销
+func BenchmarkSave(b *testing.B) {
+	benchmarkSave(b, false)
+}
+
+func BenchmarkSaveSync(b *testing.B) {
+	benchmarkSave(b, true)
+}
+
+// 每次用户操作前都可以做快照
+func BenchmarkSnapshot(b *testing.B) {
+	hostsEdit, err := NewFromReader(strings.NewReader(largeHostsContent(100000)), false)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		hostsEdit.Snapshot()
+	}
+}
@@ -0,0 +1,103 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+// CommentAll disables every active entry by turning it into a comment line,
+// so "127.0.0.1 localhost" becomes "# 127.0.0.1 localhost". A disabled entry
+// keeps its IP and hosts, as entries that are commented out in the file do
+// when they are loaded, and IsDisabled reports true for it. The file is
+// saved once, and only if an entry was disabled.
+func (h *HostsEdit) CommentAll() error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	changed := false
+	for _, line := range h.Lines {
+		if line.IsComment || line.UndefinedRowsRawStr != "" || line.IP == "" {
+			continue
+		}
+		line.IsComment = true
+		line.touch()
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	return h.saveToFile(operation{name: "CommentAll"})
+}
+
+// UncommentAll enables every comment line that holds a valid entry, such as
+// "# 127.0.0.1 localhost", and leaves other comments alone. If the enabled
+// entries would map a host on more than one line, a *ParseError wrapping
+// ErrDuplicateHost is returned and nothing is changed. The file is saved
+// once, and only if an entry was enabled.
+func (h *HostsEdit) UncommentAll() error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	enabled := make(map[int]*Line)
+	seen := make(map[string]struct{})
+	for i, line := range h.Lines {
+		entry := line
+		if line.IsComment {
+			entry = commentedEntry(line)
+			if entry == nil {
+				continue
+			}
+			enabled[i] = entry
+		} else if line.UndefinedRowsRawStr != "" {
+			continue
+		}
+		for j, host := range entry.hosts {
+			if _, ok := seen[lowerASCII(host)]; ok {
+				err := &ParseError{Line: line.num, Text: host, Err: ErrDuplicateHost}
+				if entry == line {
+					err.Column, err.Length = line.fieldSpan(j + 1)
+				}
+				return err
+			}
+			seen[lowerASCII(host)] = struct{}{}
+		}
+	}
+	if len(enabled) == 0 {
+		return nil
+	}
+
+	for i, entry := range enabled {
+		line := h.Lines[i]
+		line.IsComment = false
+		line.UndefinedRowsRawStr = ""
+		line.setIP(entry.IP)
+		line.setHosts(entry.hosts)
+		line.touch()
+	}
+	return h.saveToFile(operation{name: "UncommentAll"})
+}
+
+// commentedEntry returns the entry held by a comment line, or nil if the
+// comment is not a valid entry.
+func commentedEntry(line *Line) *Line {
+	if line.IsDisabled() {
+		return line
+	}
+	entry, err := ParseLine(line.UndefinedRowsRawStr)
+	if err != nil || entry.IP == "" {
+		return nil
+	}
+	return entry
+}
@@ -0,0 +1,127 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+// 测试CommentAll方法
+func TestCommentAll(t *testing.T) {
+	hostsContent := "# local\n127.0.0.1 localhost\n::1 ipv6host\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	err = hostsEdit.CommentAll()
+	if err != nil {
+		t.Fatalf("CommentAll() error = %v", err)
+	}
+	if hostsEdit.Exists("localhost") {
+		t.Errorf("Exists(localhost) = true after CommentAll; want false")
+	}
+
+	want := "# local\n# 127.0.0.1 localhost\n# ::1 ipv6host\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("CommentAll() wrote %q; want %q", contentBytes, want)
+	}
+
+	updatedHostsEdit, _ := New(filePath, false)
+	for _, line := range updatedHostsEdit.Lines[1:] {
+		if !line.IsDisabled() {
+			t.Errorf("line %q is not disabled after reload", FormatLine(line))
+		}
+	}
+}
+
+// 测试UncommentAll方法
+func TestUncommentAll(t *testing.T) {
+	hostsContent := "# local\n# 127.0.0.1 localhost\n::1 ipv6host\n# not an entry\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	hostsEdit.Lines = append(hostsEdit.Lines, &Line{IsComment: true, UndefinedRowsRawStr: "10.0.0.1 a.test b.test"})
+	err = hostsEdit.UncommentAll()
+	if err != nil {
+		t.Fatalf("UncommentAll() error = %v", err)
+	}
+
+	want := "# local\n127.0.0.1 localhost\n::1 ipv6host\n# not an entry\n10.0.0.1 a.test b.test\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("UncommentAll() wrote %q; want %q", contentBytes, want)
+	}
+
+	// CommentAll之后UncommentAll恢复原内容
+	if err := hostsEdit.CommentAll(); err != nil {
+		t.Fatalf("CommentAll() error = %v", err)
+	}
+	if err := hostsEdit.UncommentAll(); err != nil {
+		t.Fatalf("UncommentAll() error = %v", err)
+	}
+	contentBytes, _ = os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("CommentAll() then UncommentAll() wrote %q; want %q", contentBytes, want)
+	}
+}
+
+// 测试UncommentAll发现重复主机时不做修改
+func TestUncommentAllDuplicate(t *testing.T) {
+	hostsContent := "127.0.0.1 localhost\n# 127.0.0.2 localhost\n# 10.0.0.1 a.test\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	err = hostsEdit.UncommentAll()
+	if !errors.Is(err, ErrDuplicateHost) {
+		t.Errorf("UncommentAll() error = %v; want %v", err, ErrDuplicateHost)
+	}
+	if hostsEdit.Exists("a.test") {
+		t.Errorf("UncommentAll() enabled a.test despite the duplicate")
+	}
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != hostsContent {
+		t.Errorf("UncommentAll() changed the file to %q", contentBytes)
+	}
+}
+
+// 测试大小写不同的主机也算重复
+func TestUncommentAllDuplicateMixedCase(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n# 127.0.0.2 LocalHost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	if err := hostsEdit.UncommentAll(); !errors.Is(err, ErrDuplicateHost) {
+		t.Errorf("UncommentAll() error = %v; want %v", err, ErrDuplicateHost)
+	}
+}
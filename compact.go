@@ -0,0 +1,126 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+// Compact removes blank lines, lines that are neither comments nor valid
+// entries, and comment lines that repeat an earlier comment line of the
+// file. Entry lines are kept as they are. The file is saved once, and only if
+// a line was removed. CompactWith removes less and keeps the layout of the
+// file.
+func (h *HostsEdit) Compact() error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	seen := make(map[string]bool)
+	var lines []*Line
+	for _, line := range h.Lines {
+		if line.isBlank() {
+			continue
+		}
+		if !line.IsComment && line.UndefinedRowsRawStr != "" {
+			continue
+		}
+		if line.IsComment {
+			text := formatLine(line, renderConfig{})
+			if seen[text] {
+				continue
+			}
+			seen[text] = true
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == len(h.Lines) {
+		return nil
+	}
+
+	h.Lines = lines
+	return h.saveToFile(operation{name: "Compact"})
+}
+
+// CompactOptions configures CompactWith.
+type CompactOptions struct {
+	// RemoveOrphanComments also removes comment lines that are not followed
+	// by an entry, such as the header of a section whose entries have all
+	// been deleted. A comment is orphaned when the first line after it that
+	// is not a comment is blank, malformed, or missing.
+	RemoveOrphanComments bool
+}
+
+// CompactResult reports the lines removed by CompactWith.
+type CompactResult struct {
+	BlankLines        int
+	DuplicateComments int
+	OrphanComments    int
+}
+
+// CompactWith collapses every run of blank lines to a single one and removes
+// comment lines that repeat the comment line directly above them. Entry
+// lines, including disabled ones, are never changed. The file is saved once,
+// and only if a line was removed.
+func (h *HostsEdit) CompactWith(opts CompactOptions) (CompactResult, error) {
+	if h.readOnly {
+		return CompactResult{}, ErrReadOnly
+	}
+	var result CompactResult
+
+	orphan := make([]bool, len(h.Lines))
+	if opts.RemoveOrphanComments {
+		followed := false
+		for i := len(h.Lines) - 1; i >= 0; i-- {
+			line := h.Lines[i]
+			if isPlainComment(line) {
+				orphan[i] = !followed
+				continue
+			}
+			followed = line.UndefinedRowsRawStr == "" && line.IP != ""
+		}
+	}
+
+	var lines []*Line
+	for i, line := range h.Lines {
+		if orphan[i] {
+			result.OrphanComments++
+			continue
+		}
+		var prev *Line
+		if len(lines) > 0 {
+			prev = lines[len(lines)-1]
+		}
+		if line.isBlank() && prev != nil && prev.isBlank() {
+			result.BlankLines++
+			continue
+		}
+		if isPlainComment(line) && prev != nil && isPlainComment(prev) &&
+			formatLine(line, renderConfig{}) == formatLine(prev, renderConfig{}) {
+			result.DuplicateComments++
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == len(h.Lines) {
+		return result, nil
+	}
+
+	h.Lines = lines
+	return result, h.saveToFile(operation{name: "CompactWith"})
+}
+
+// isPlainComment reports whether the line is a comment that is not a
+// disabled entry.
+func isPlainComment(line *Line) bool {
+	return line.IsComment && !line.IsDisabled()
+}
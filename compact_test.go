@@ -0,0 +1,129 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"os"
+	"testing"
+)
+
+// 测试Compact方法
+func TestCompact(t *testing.T) {
+	hostsContent := "# Added by tool\n127.0.0.1 localhost\n\n\nnot an entry\n# Added by tool\n10.0.0.1 api.test\n#   Added by tool\n# other\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false, WithPreserveFormatting())
+	err = hostsEdit.Compact()
+	if err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+
+	want := "# Added by tool\n127.0.0.1 localhost\n10.0.0.1 api.test\n# other\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("Compact() wrote %q; want %q", contentBytes, want)
+	}
+
+	if len(hostsEdit.Lines) != 4 {
+		t.Errorf("Expected 4 lines after Compact, got %d", len(hostsEdit.Lines))
+	}
+}
+
+// 测试CompactWith方法
+func TestCompactWith(t *testing.T) {
+	hostsContent := `# Added by X
+# Added by X
+127.0.0.1 localhost
+
+
+# old section
+
+# Added by X
+10.0.0.1 api.test
+10.0.0.1 api.test
+
+
+
+# 10.0.0.2 off.test
+# 10.0.0.2 off.test
+# trailing
+`
+	tests := []struct {
+		name   string
+		opts   CompactOptions
+		want   string
+		result CompactResult
+	}{
+		{
+			name: "default",
+			opts: CompactOptions{},
+			want: `# Added by X
+127.0.0.1 localhost
+
+# old section
+
+# Added by X
+10.0.0.1 api.test
+10.0.0.1 api.test
+
+# 10.0.0.2 off.test
+# 10.0.0.2 off.test
+# trailing
+`,
+			result: CompactResult{BlankLines: 3, DuplicateComments: 1},
+		},
+		{
+			name: "orphans",
+			opts: CompactOptions{RemoveOrphanComments: true},
+			want: `# Added by X
+127.0.0.1 localhost
+
+# Added by X
+10.0.0.1 api.test
+10.0.0.1 api.test
+
+# 10.0.0.2 off.test
+# 10.0.0.2 off.test
+`,
+			result: CompactResult{BlankLines: 4, DuplicateComments: 1, OrphanComments: 2},
+		},
+	}
+	for _, tt := range tests {
+		filePath, err := createTestHostsFile(hostsContent)
+		if err != nil {
+			t.Fatalf("Failed to create test hosts file: %v", err)
+		}
+		defer os.Remove(filePath)
+
+		hostsEdit, _ := New(filePath, false, WithPreserveFormatting())
+		result, err := hostsEdit.CompactWith(tt.opts)
+		if err != nil {
+			t.Fatalf("%s: CompactWith() error = %v", tt.name, err)
+		}
+		if result != tt.result {
+			t.Errorf("%s: CompactWith() = %+v; want %+v", tt.name, result, tt.result)
+		}
+		contentBytes, _ := os.ReadFile(filePath)
+		if string(contentBytes) != tt.want {
+			t.Errorf("%s: CompactWith() wrote %q; want %q", tt.name, contentBytes, tt.want)
+		}
+	}
+}
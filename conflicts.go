@@ -0,0 +1,88 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import "net"
+
+// Mapping is one active entry of a host: the IP address it maps the host to
+// and the line number of the entry in the file it was loaded from, 0 for a
+// line added since.
+type Mapping struct {
+	IP   string
+	Line int
+}
+
+// Conflict is a host with active entries for more than one IP address of the
+// same family.
+type Conflict struct {
+	Host     string
+	Mappings []Mapping // every entry of the host, in file order
+	// Effective is the entry the operating system uses, the first one.
+	Effective Mapping
+}
+
+// Conflicts returns the hosts that are mapped to different IP addresses of the
+// same family by different active entries, in the order the hosts first
+// appear. A host with one IPv4 and one IPv6 address, such as localhost, is
+// not a conflict. Addresses are compared after IPNormalize, so ::1 and 0::1
+// do not conflict, and hosts are compared ignoring case. Host is written as
+// in its first entry.
+func (h *HostsEdit) Conflicts() []Conflict {
+	var order []string
+	mappings := make(map[string][]Mapping)
+	for _, line := range h.Lines {
+		if !isActive(line) {
+			continue
+		}
+		for _, host := range line.hosts {
+			// 主机名不区分大小写
+			key := lowerASCII(host)
+			if _, ok := mappings[key]; !ok {
+				order = append(order, host)
+			}
+			mappings[key] = append(mappings[key], Mapping{IP: line.IP, Line: line.num})
+		}
+	}
+
+	var conflicts []Conflict
+	for _, host := range order {
+		m := mappings[lowerASCII(host)]
+		if hasConflict(m) {
+			conflicts = append(conflicts, Conflict{Host: host, Mappings: m, Effective: m[0]})
+		}
+	}
+	return conflicts
+}
+
+// hasConflict reports whether mappings contain two different addresses of
+// the same family.
+func hasConflict(mappings []Mapping) bool {
+	first := make(map[Family]string)
+	for _, m := range mappings {
+		ip := IPNormalize(m.IP)
+		family := IPv6
+		if net.ParseIP(ip).To4() != nil {
+			family = IPv4
+		}
+		if v, ok := first[family]; !ok {
+			first[family] = ip
+		} else if v != ip {
+			return true
+		}
+	}
+	return false
+}
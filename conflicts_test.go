@@ -0,0 +1,74 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+// 测试Conflicts方法
+func TestConflicts(t *testing.T) {
+	hostsContent := `127.0.0.1 localhost api.test
+::1 localhost ipv6host
+# 10.0.0.9 api.test
+0::1 ipv6host
+10.0.0.1 api.test
+10.0.0.2 db.test
+fe80::1 router.test
+fe80::2 router.test
+`
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	got := hostsEdit.Conflicts()
+	want := []Conflict{
+		{
+			Host:      "api.test",
+			Mappings:  []Mapping{{"127.0.0.1", 1}, {"10.0.0.1", 5}},
+			Effective: Mapping{"127.0.0.1", 1},
+		},
+		{
+			Host:      "router.test",
+			Mappings:  []Mapping{{"fe80::1", 7}, {"fe80::2", 8}},
+			Effective: Mapping{"fe80::1", 7},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Conflicts() = %+v; want %+v", got, want)
+	}
+}
+
+// 测试大小写不同的同一主机也算冲突
+func TestConflictsMixedCase(t *testing.T) {
+	filePath, err := createTestHostsFile("10.0.0.1 Api.test\n10.0.0.2 api.test\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	conflicts := hostsEdit.Conflicts()
+	if len(conflicts) != 1 || conflicts[0].Host != "Api.test" || len(conflicts[0].Mappings) != 2 {
+		t.Errorf("Conflicts() = %+v; want one conflict for Api.test", conflicts)
+	}
+}
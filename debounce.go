@@ -0,0 +1,160 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"sync"
+	"time"
+)
+
+// debouncer holds the pending write of SaveDebounced.
+type debouncer struct {
+	mu      sync.Mutex
+	timer   *time.Timer
+	pending bool
+	content []byte
+	write   func(content []byte) error
+	err     error // error of the last background write, reported once
+
+	writing sync.Mutex // serializes writes
+}
+
+// SaveDebounced schedules a save of the hosts file after d, and cancels the
+// save scheduled by a previous call that has not happened yet, so a burst of
+// calls results in a single write once the calls stop for d. The content is
+// rendered, and BeforeSave hooks run, when SaveDebounced is called; the
+// write itself happens on another goroutine and does not touch h, so h can
+// be used in the meantime. Close writes a pending save immediately, and any
+// other save cancels it.
+//
+// Once the background write has succeeded, the changes are reported to the
+// OnChange functions and the channels of Subscribe and Watch, on the
+// goroutine of the write. The lines count as saved, as for the other saves,
+// from the time SaveDebounced is called.
+//
+// An error of the background write is returned by the next call to
+// SaveDebounced or Close.
+func (h *HostsEdit) SaveDebounced(d time.Duration) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	h.syncHostFields()
+	h.splitLongLines()
+	h.annotateChanges()
+	h.addHeader()
+	if err := h.runBeforeSave(); err != nil {
+		return err
+	}
+	content := h.render()
+
+	if h.debounce == nil {
+		h.debounce = &debouncer{}
+	}
+	db := h.debounce
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	err := db.err
+	db.err = nil
+	db.pending, db.content = true, content
+	write := h.contentWriter()
+	logger, path := h.logger, h.FilePath
+	audited := func(error) {}
+	if h.auditLog != nil {
+		audited = h.auditWriter(operation{name: "SaveDebounced"}, content)
+	}
+	notify := h.changeNotifier()
+	db.write = func(content []byte) error {
+		// 在后台写入时才记录日志
+		logged := startWriteLog(logger, path, operation{name: "SaveDebounced"})
+		strategy, err := write(content)
+		logged(strategy, err)
+		if err == nil {
+			notify()
+		}
+		audited(err)
+		return err
+	}
+	if db.timer != nil {
+		db.timer.Stop()
+	}
+	db.timer = time.AfterFunc(d, db.flush)
+	// 后台写入不能修改h，在这里把行标记为已保存；
+	// 另一个协程写入了文件，下次保存不能再走追加的方式
+	h.markSynced(int64(len(content)))
+	h.synced = nil
+	return err
+}
+
+// Close writes the save scheduled by SaveDebounced, if any, and waits for a
+// write in progress to finish. It returns the error of that write or of an
+// earlier background write.
+func (h *HostsEdit) Close() error {
+	db := h.debounce
+	if db == nil {
+		return nil
+	}
+	db.mu.Lock()
+	if db.timer != nil {
+		db.timer.Stop()
+		db.timer = nil
+	}
+	db.mu.Unlock()
+
+	db.flush()
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	err := db.err
+	db.err = nil
+	return err
+}
+
+// cancel drops the pending content, if any, and waits for a write in
+// progress to finish, so that a newer save is not overwritten by it.
+func (db *debouncer) cancel() {
+	db.mu.Lock()
+	if db.timer != nil {
+		db.timer.Stop()
+		db.timer = nil
+	}
+	db.pending, db.content = false, nil
+	db.mu.Unlock()
+
+	db.writing.Lock()
+	db.writing.Unlock()
+}
+
+// flush writes the pending content, if any.
+func (db *debouncer) flush() {
+	db.writing.Lock()
+	defer db.writing.Unlock()
+
+	db.mu.Lock()
+	pending, content, write := db.pending, db.content, db.write
+	db.pending, db.content = false, nil
+	db.mu.Unlock()
+	if !pending {
+		return
+	}
+
+	err := write(content)
+	if err != nil {
+		db.mu.Lock()
+		db.err = err
+		db.mu.Unlock()
+	}
+}
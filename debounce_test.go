@@ -0,0 +1,179 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingStore 记录保存次数的内存存储
+type countingStore struct {
+	mu    sync.Mutex
+	mem   MemStore
+	saves int
+}
+
+func (s *countingStore) Load() (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mem.Load()
+}
+
+func (s *countingStore) Save(content []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saves++
+	return s.mem.Save(content)
+}
+
+func (s *countingStore) state() (string, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return string(s.mem.Content), s.saves
+}
+
+// 测试SaveDebounced合并多次保存
+func TestSaveDebounced(t *testing.T) {
+	store := &countingStore{mem: MemStore{Content: []byte("127.0.0.1 localhost\n")}}
+	hostsEdit, err := NewWithStore(store, false)
+	if err != nil {
+		t.Fatalf("NewWithStore() error = %v", err)
+	}
+
+	line := hostsEdit.GetLineForHost("localhost")
+	for _, host := range []string{"a.test", "b.test", "c.test"} {
+		line.AddHost(host)
+		if err := hostsEdit.SaveDebounced(time.Hour); err != nil {
+			t.Fatalf("SaveDebounced() error = %v", err)
+		}
+	}
+	if _, saves := store.state(); saves != 0 {
+		t.Errorf("SaveDebounced() saved %d times before the quiet period", saves)
+	}
+
+	if err := hostsEdit.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	content, saves := store.state()
+	if saves != 1 || content != "127.0.0.1 localhost a.test b.test c.test\n" {
+		t.Errorf("after Close() content = %q with %d saves; want all hosts with 1 save", content, saves)
+	}
+
+	// 静默期结束后自动保存
+	line.AddHost("d.test")
+	if err := hostsEdit.SaveDebounced(10 * time.Millisecond); err != nil {
+		t.Fatalf("SaveDebounced() error = %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, saves = store.state(); saves == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if saves != 2 {
+		t.Errorf("SaveDebounced() did not save after the quiet period")
+	}
+
+	// 其他保存会取消尚未执行的保存
+	line.AddHost("e.test")
+	hostsEdit.SaveDebounced(time.Hour)
+	if err := hostsEdit.Edit("f.test", "10.0.0.1"); err != nil {
+		t.Fatalf("Edit(f.test, 10.0.0.1) failed with error: %v", err)
+	}
+	hostsEdit.Close()
+	if _, saves = store.state(); saves != 3 {
+		t.Errorf("saved %d times; want 3", saves)
+	}
+}
+
+// 测试SaveDebounced在后台写入成功后通知订阅者
+func TestSaveDebouncedNotify(t *testing.T) {
+	store := &countingStore{mem: MemStore{Content: []byte("127.0.0.1 localhost\n")}}
+	hostsEdit, err := NewWithStore(store, false)
+	if err != nil {
+		t.Fatalf("NewWithStore() error = %v", err)
+	}
+	ch := make(chan HostEvent, 10)
+	if err := hostsEdit.Subscribe(ch); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	hostsEdit.GetLineForHost("localhost").AddHost("a.test")
+	if err := hostsEdit.SaveDebounced(10 * time.Millisecond); err != nil {
+		t.Fatalf("SaveDebounced() error = %v", err)
+	}
+	select {
+	case e := <-ch:
+		if e.Type != OpAdd || e.Host != "a.test" || e.IP != "127.0.0.1" {
+			t.Errorf("event = %+v; want a.test added", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no event after the debounced save")
+	}
+	if err := hostsEdit.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// 已经通知过的变更不会再次通知
+	if err := hostsEdit.Edit("b.test", "10.0.0.1"); err != nil {
+		t.Fatalf("Edit(b.test, 10.0.0.1) failed with error: %v", err)
+	}
+	if e := <-ch; e.Host != "b.test" {
+		t.Errorf("event after Edit() = %+v; want only b.test", e)
+	}
+	if len(ch) != 0 {
+		t.Errorf("%d unexpected events after Edit()", len(ch))
+	}
+}
+
+// 测试SaveDebounced在后台发送Watch事件时不访问正在修改的行
+func TestSaveDebouncedWatch(t *testing.T) {
+	store := &countingStore{mem: MemStore{Content: []byte("127.0.0.1 localhost\n")}}
+	hostsEdit, err := NewWithStore(store, false)
+	if err != nil {
+		t.Fatalf("NewWithStore() error = %v", err)
+	}
+	events, cancel := hostsEdit.Watch()
+	defer cancel()
+
+	// 后台写入的同时继续修改行，用-race运行时检查数据竞争
+	line := hostsEdit.GetLineForHost("localhost")
+	for i := 0; i < 20; i++ {
+		line.AddHost(fmt.Sprintf("host%d.test", i))
+		if err := hostsEdit.SaveDebounced(0); err != nil {
+			t.Fatalf("SaveDebounced() error = %v", err)
+		}
+	}
+	hostsEdit.Lines = append([]*Line{NewLine("10.0.0.1", "first.test")}, hostsEdit.Lines...)
+	if err := hostsEdit.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	seen := make(map[string]int)
+	for len(events) > 0 {
+		e := <-events
+		seen[e.Host] = e.Line
+	}
+	if line, ok := seen["host19.test"]; !ok || line != 0 {
+		t.Errorf("event for host19.test: Line = %d, sent = %v; want line 0 of the saved lines", line, ok)
+	}
+}
@@ -0,0 +1,156 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+)
+
+// osHostname returns the name of the machine, replaced in tests.
+var osHostname = os.Hostname
+
+// ipv6Defaults are the conventional IPv6 entries of a hosts file.
+var ipv6Defaults = []struct {
+	ip    string
+	hosts []string
+}{
+	{"::1", []string{"localhost", "ip6-localhost", "ip6-loopback"}},
+	{"ff02::1", []string{"ip6-allnodes"}},
+	{"ff02::2", []string{"ip6-allrouters"}},
+}
+
+// EnsureIPv6Defaults adds the conventional IPv6 entries that are missing:
+//
+//	::1     localhost ip6-localhost ip6-loopback
+//	ff02::1 ip6-allnodes
+//	ff02::2 ip6-allrouters
+//
+// A host counts as present if an active entry maps it to an IPv6 address,
+// or to any address other than an IPv4 loopback address, so that a
+// customized entry is never shadowed by a default one, while "::1 localhost"
+// is still added when only "127.0.0.1 localhost" exists. Existing lines are
+// never modified. The missing hosts are added on new lines right after the
+// last IPv4 loopback entry, or at the top of the file if there is none. It returns the hosts added and saves the
+// file once, only if a host was added.
+func (h *HostsEdit) EnsureIPv6Defaults() (added []string, err error) {
+	if h.readOnly {
+		return nil, ErrReadOnly
+	}
+	var newLines []*Line
+	for _, d := range ipv6Defaults {
+		var missing []string
+		for _, host := range d.hosts {
+			if !h.ipv6DefaultPresent(host) {
+				missing = append(missing, host)
+			}
+		}
+		if len(missing) > 0 {
+			newLines = append(newLines, NewLine(d.ip, missing...))
+			added = append(added, missing...)
+		}
+	}
+	if len(newLines) == 0 {
+		return nil, nil
+	}
+
+	at := h.loopbackEnd()
+	h.Lines = append(h.Lines[:at], append(newLines, h.Lines[at:]...)...)
+	err = h.saveToFile(operation{name: "EnsureIPv6Defaults"})
+	if err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// ipv6DefaultPresent reports whether an active entry maps host to an address
+// other than an IPv4 loopback address, as EnsureIPv6Defaults describes.
+func (h *HostsEdit) ipv6DefaultPresent(host string) bool {
+	for _, line := range h.Lines {
+		if !isActive(line) || !line.HasHost(host) {
+			continue
+		}
+		if addr, ok := parseAddr(line.IP); !ok || !addr.Is4() || !addr.IsLoopback() {
+			return true
+		}
+	}
+	return false
+}
+
+// WithMachineHostname makes EnsureLocalhostDefaults also map the name of
+// the machine, as reported by os.Hostname, to 127.0.0.1.
+func WithMachineHostname() Option {
+	return func(h *HostsEdit) {
+		h.machineHostname = true
+	}
+}
+
+// EnsureLocalhostDefaults makes sure that localhost maps to 127.0.0.1, as
+// well as the machine's host name with WithMachineHostname. A host counts as
+// present if any active line maps it to 127.0.0.1. The missing hosts are
+// added on one new line at the top of the file, after the leading comment
+// lines, so that they take precedence over any other address; existing
+// lines are never modified or moved. It returns the hosts added and saves the
+// file once, only if a host was added.
+func (h *HostsEdit) EnsureLocalhostDefaults() (added []string, err error) {
+	if h.readOnly {
+		return nil, ErrReadOnly
+	}
+	hosts := []string{"localhost"}
+	if h.machineHostname {
+		name, err := osHostname()
+		if err != nil {
+			return nil, fmt.Errorf("get host name: %w", err)
+		}
+		if name != "" && !equalFoldASCII(name, "localhost") {
+			hosts = append(hosts, name)
+		}
+	}
+	for _, host := range hosts {
+		if !h.Contains(host, "127.0.0.1") {
+			added = append(added, host)
+		}
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+
+	at := h.leadingCommentsEnd()
+	h.Lines = append(h.Lines[:at], append([]*Line{NewLine("127.0.0.1", added...)}, h.Lines[at:]...)...)
+	err = h.saveToFile(operation{name: "EnsureLocalhostDefaults"})
+	if err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// loopbackEnd returns the index after the last active IPv4 loopback entry,
+// or 0 if there is none.
+func (h *HostsEdit) loopbackEnd() int {
+	end := 0
+	for i, line := range h.Lines {
+		if !isActive(line) {
+			continue
+		}
+		addr, err := netip.ParseAddr(line.IP)
+		if err == nil && addr.Is4() && addr.IsLoopback() {
+			end = i + 1
+		}
+	}
+	return end
+}
@@ -0,0 +1,147 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+// 测试EnsureIPv6Defaults方法
+func TestEnsureIPv6Defaults(t *testing.T) {
+	hostsContent := "# header\n127.0.0.1 localhost\n127.0.1.1 myhost\n::1 localhost\n10.0.0.1 ip6-allrouters\nfe80::1 ip6-allnodes\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	added, err := hostsEdit.EnsureIPv6Defaults()
+	if err != nil {
+		t.Fatalf("EnsureIPv6Defaults() error = %v", err)
+	}
+	wantAdded := []string{"ip6-localhost", "ip6-loopback"}
+	if !reflect.DeepEqual(added, wantAdded) {
+		t.Errorf("EnsureIPv6Defaults() = %v; want %v", added, wantAdded)
+	}
+
+	want := "# header\n127.0.0.1 localhost\n127.0.1.1 myhost\n::1 ip6-localhost ip6-loopback\n::1 localhost\n10.0.0.1 ip6-allrouters\nfe80::1 ip6-allnodes\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("EnsureIPv6Defaults() wrote %q; want %q", contentBytes, want)
+	}
+
+	// 自定义的条目不被默认条目覆盖
+	for host, wantIP := range map[string]string{"ip6-allrouters": "10.0.0.1", "ip6-allnodes": "fe80::1"} {
+		if ip, ok := hostsEdit.Get(host); !ok || ip != wantIP {
+			t.Errorf("Get(%s) = %v, %v; want the custom %v", host, ip, ok, wantIP)
+		}
+	}
+
+	// 已经完整时不做任何修改
+	added, err = hostsEdit.EnsureIPv6Defaults()
+	if err != nil || len(added) != 0 {
+		t.Errorf("second EnsureIPv6Defaults() = %v, %v; want nothing added", added, err)
+	}
+}
+
+// 测试没有IPv4本地回环条目时插入到文件开头
+func TestEnsureIPv6DefaultsEmpty(t *testing.T) {
+	filePath, err := createTestHostsFile("10.0.0.1 a.test\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	if _, err := hostsEdit.EnsureIPv6Defaults(); err != nil {
+		t.Fatalf("EnsureIPv6Defaults() error = %v", err)
+	}
+	want := "::1 localhost ip6-localhost ip6-loopback\nff02::1 ip6-allnodes\nff02::2 ip6-allrouters\n10.0.0.1 a.test\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("EnsureIPv6Defaults() wrote %q; want %q", contentBytes, want)
+	}
+}
+
+// 测试EnsureLocalhostDefaults方法
+func TestEnsureLocalhostDefaults(t *testing.T) {
+	oldHostname := osHostname
+	osHostname = func() (string, error) { return "myhost", nil }
+	defer func() { osHostname = oldHostname }()
+
+	filePath, err := createTestHostsFile("# header\n10.0.0.1 localhost\n::1 localhost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false, WithMachineHostname())
+	added, err := hostsEdit.EnsureLocalhostDefaults()
+	if err != nil {
+		t.Fatalf("EnsureLocalhostDefaults() error = %v", err)
+	}
+	if want := []string{"localhost", "myhost"}; !reflect.DeepEqual(added, want) {
+		t.Errorf("EnsureLocalhostDefaults() = %v; want %v", added, want)
+	}
+
+	want := "# header\n127.0.0.1 localhost myhost\n10.0.0.1 localhost\n::1 localhost\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("EnsureLocalhostDefaults() wrote %q; want %q", contentBytes, want)
+	}
+
+	// 已经完整时不做任何修改
+	added, err = hostsEdit.EnsureLocalhostDefaults()
+	if err != nil || len(added) != 0 {
+		t.Errorf("second EnsureLocalhostDefaults() = %v, %v; want nothing added", added, err)
+	}
+
+	// 没有WithMachineHostname时只检查localhost
+	os.WriteFile(filePath, []byte("10.0.0.1 a.test\n127.0.0.1 localhost\n"), 0644)
+	hostsEdit, _ = New(filePath, false)
+	added, err = hostsEdit.EnsureLocalhostDefaults()
+	if err != nil || len(added) != 0 {
+		t.Errorf("EnsureLocalhostDefaults() = %v, %v; want nothing added", added, err)
+	}
+}
+
+// 测试只有IPv4的localhost时也添加IPv6的localhost
+func TestEnsureIPv6DefaultsIPv4Localhost(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n10.0.0.1 a.test\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	added, err := hostsEdit.EnsureIPv6Defaults()
+	if err != nil {
+		t.Fatalf("EnsureIPv6Defaults() error = %v", err)
+	}
+	wantAdded := []string{"localhost", "ip6-localhost", "ip6-loopback", "ip6-allnodes", "ip6-allrouters"}
+	if !reflect.DeepEqual(added, wantAdded) {
+		t.Errorf("EnsureIPv6Defaults() = %v; want %v", added, wantAdded)
+	}
+	want := "127.0.0.1 localhost\n::1 localhost ip6-localhost ip6-loopback\nff02::1 ip6-allnodes\nff02::2 ip6-allrouters\n10.0.0.1 a.test\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("EnsureIPv6Defaults() wrote %q; want %q", contentBytes, want)
+	}
+}
@@ -0,0 +1,161 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+package hostedit
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Entry is a single mapping of a host to an IP address.
+type Entry struct {
+	IP   string
+	Host string
+}
+
+// Change is a host whose IP address differs between two states.
+type Change struct {
+	Host  string
+	OldIP string
+	NewIP string
+}
+
+// Diff describes how the effective mapping of hosts, the first entry for each
+// host as the operating system sees it, differs between two hosts files.
+type Diff struct {
+	Added   []Entry
+	Removed []Entry
+	Changed []Change
+}
+
+// ErrConflict means the current content of the hosts file does not match
+// what an operation expected.
+var ErrConflict = errors.New("conflict")
+
+// ConflictError is returned by Apply when a host no longer has the IP address
+// the diff expects. It matches ErrConflict with errors.Is.
+type ConflictError struct {
+	Host string
+	Want string // the IP address expected, "" if the host was expected to be absent
+	Got  string // the current IP address, "" if the host is absent
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%v: host %q maps to %q, expected %q", ErrConflict, e.Host, e.Got, e.Want)
+}
+
+func (e *ConflictError) Unwrap() error {
+	return ErrConflict
+}
+
+// Diff returns the changes that turn the mapping of h into the mapping of
+// other. Added and Changed follow the order of other, Removed that of h.
+func (h *HostsEdit) Diff(other *HostsEdit) Diff {
+	from, fromOrder := h.effective()
+	to, toOrder := other.effective()
+	return diffEffective(from, fromOrder, to, toOrder)
+}
+
+// diffEffective returns the changes between two results of effective.
+func diffEffective(from map[string]string, fromOrder []string, to map[string]string, toOrder []string) Diff {
+	var d Diff
+	for _, host := range toOrder {
+		key := lowerASCII(host)
+		oldIP, ok := from[key]
+		if !ok {
+			d.Added = append(d.Added, Entry{IP: to[key], Host: host})
+		} else if oldIP != to[key] {
+			d.Changed = append(d.Changed, Change{Host: host, OldIP: oldIP, NewIP: to[key]})
+		}
+	}
+	for _, host := range fromOrder {
+		key := lowerASCII(host)
+		if _, ok := to[key]; !ok {
+			d.Removed = append(d.Removed, Entry{IP: from[key], Host: host})
+		}
+	}
+	return d
+}
+
+// Apply makes the changes described by d and saves the file once. Before
+// changing anything, it checks that every host still has the IP address d
+// expects and returns a *ConflictError if not. Changes that are already in
+// place are not conflicts. Either the whole diff is applied and saved, or
+// nothing is changed.
+func (h *HostsEdit) Apply(d Diff) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	current, _ := h.effective()
+	for _, e := range d.Added {
+		if got, ok := current[lowerASCII(e.Host)]; ok && !sameIP(got, e.IP) {
+			return &ConflictError{Host: e.Host, Want: "", Got: got}
+		}
+	}
+	for _, e := range d.Removed {
+		if got, ok := current[lowerASCII(e.Host)]; ok && !sameIP(got, e.IP) {
+			return &ConflictError{Host: e.Host, Want: e.IP, Got: got}
+		}
+	}
+	for _, c := range d.Changed {
+		if got := current[lowerASCII(c.Host)]; !sameIP(got, c.OldIP) && !sameIP(got, c.NewIP) {
+			return &ConflictError{Host: c.Host, Want: c.OldIP, Got: got}
+		}
+	}
+
+	backup := cloneLines(h.Lines)
+	changed := false
+	for _, e := range d.Removed {
+		changed = h.remove(e.Host) || changed
+	}
+	for _, e := range d.Added {
+		changed = h.set(e.Host, e.IP) || changed
+	}
+	for _, c := range d.Changed {
+		changed = h.set(c.Host, c.NewIP) || changed
+	}
+	if !changed {
+		return nil
+	}
+
+	err := h.saveToFile(operation{name: "Apply"})
+	if err != nil {
+		h.Lines = backup
+		return err
+	}
+	return nil
+}
+
+// effective returns the IP address the operating system uses for each host,
+// keyed by the host in lowercase since host names are not case-sensitive,
+// and the hosts as first written in the order they first appear.
+func (h *HostsEdit) effective() (map[string]string, []string) {
+	ips := make(map[string]string)
+	var order []string
+	for _, line := range h.Lines {
+		if line.IsComment || line.UndefinedRowsRawStr != "" {
+			continue
+		}
+		for _, host := range line.hosts {
+			key := lowerASCII(host)
+			if _, ok := ips[key]; !ok {
+				ips[key] = line.IP
+				order = append(order, host)
+			}
+		}
+	}
+	return ips, order
+}
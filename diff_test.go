@@ -0,0 +1,127 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+package hostedit
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// 测试Diff和Apply
+func TestDiffApply(t *testing.T) {
+	agentContent := `
+127.0.0.1 localhost
+10.0.0.1 api.test old.test
+10.0.0.2 web.test
+`
+	serverContent := `
+127.0.0.1 localhost
+10.0.0.9 api.test
+10.0.0.2 web.test db.test
+`
+	agentPath, err := createTestHostsFile(agentContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(agentPath)
+	serverPath, err := createTestHostsFile(serverContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(serverPath)
+
+	agent, _ := New(agentPath, false)
+	server, _ := New(serverPath, false)
+
+	d := agent.Diff(server)
+	want := Diff{
+		Added:   []Entry{{IP: "10.0.0.2", Host: "db.test"}},
+		Removed: []Entry{{IP: "10.0.0.1", Host: "old.test"}},
+		Changed: []Change{{Host: "api.test", OldIP: "10.0.0.1", NewIP: "10.0.0.9"}},
+	}
+	if !reflect.DeepEqual(d, want) {
+		t.Fatalf("Diff() = %+v; want %+v", d, want)
+	}
+
+	err = agent.Apply(d)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	updated, _ := New(agentPath, false)
+	if rest := updated.Diff(server); !reflect.DeepEqual(rest, Diff{}) {
+		t.Errorf("Diff() after Apply = %+v; want empty", rest)
+	}
+
+	// 重复应用同一个补丁不算冲突
+	if err := updated.Apply(d); err != nil {
+		t.Errorf("Apply() again error = %v", err)
+	}
+}
+
+// 测试Apply在前置条件不满足时不做任何修改
+func TestApplyConflict(t *testing.T) {
+	hostsContent := "10.0.0.5 api.test\n10.0.0.2 web.test\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	d := Diff{
+		Removed: []Entry{{IP: "10.0.0.2", Host: "web.test"}},
+		Changed: []Change{{Host: "api.test", OldIP: "10.0.0.1", NewIP: "10.0.0.9"}},
+	}
+	err = hostsEdit.Apply(d)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || !errors.Is(err, ErrConflict) {
+		t.Fatalf("Apply() error = %v; want *ConflictError", err)
+	}
+	if conflict.Host != "api.test" || conflict.Got != "10.0.0.5" {
+		t.Errorf("Apply() conflict = %+v", conflict)
+	}
+	if !hostsEdit.Exists("web.test") {
+		t.Errorf("Apply() partially applied the diff")
+	}
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != hostsContent {
+		t.Errorf("Apply() changed the file to %q", contentBytes)
+	}
+}
+
+// 测试Diff和Apply忽略主机名的大小写
+func TestDiffApplyMixedCase(t *testing.T) {
+	filePath, err := createTestHostsFile("10.0.0.1 Api.test\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	other, _ := NewFromReader(strings.NewReader("10.0.0.1 api.test\n"), false)
+	if d := hostsEdit.Diff(other); len(d.Added)+len(d.Removed)+len(d.Changed) != 0 {
+		t.Errorf("Diff() = %+v; want no changes", d)
+	}
+
+	err = hostsEdit.Apply(Diff{Changed: []Change{{Host: "API.TEST", OldIP: "10.0.0.5", NewIP: "10.0.0.9"}}})
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || conflict.Got != "10.0.0.1" {
+		t.Errorf("Apply() error = %v; want conflict with 10.0.0.1", err)
+	}
+}
@@ -0,0 +1,85 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+// EnsureExists makes sure host maps to ip, for callers that declare the
+// entries they need rather than force them. If host already maps to ip,
+// nothing is written. If it maps to another address, a *ConflictError is
+// returned, which matches ErrConflict, and nothing is changed. Otherwise
+// host is added as Edit adds it.
+func (h *HostsEdit) EnsureExists(host, ip string) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	got, ok := h.Get(host)
+	if !ok {
+		return h.Edit(host, ip)
+	}
+	if !sameIP(got, ip) {
+		return &ConflictError{Host: host, Want: ip, Got: got}
+	}
+	return nil
+}
+
+// EnsureAbsent makes sure host has no entry. It removes host as Delete does
+// and, like Delete, returns nil without writing the file if host has no
+// entry, so it can be called whether or not host is there.
+func (h *HostsEdit) EnsureAbsent(host string) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	if !h.remove(host) {
+		return nil
+	}
+	return h.saveToFile(operation{name: "EnsureAbsent", host: host})
+}
+
+// EditIfExists changes the IP address of host to ip only if host already
+// has an entry, and reports whether it changed anything. Unlike Edit, it
+// never adds a host. If host has no entry or already maps to ip, it
+// returns false without writing the file.
+func (h *HostsEdit) EditIfExists(host, ip string) (changed bool, err error) {
+	if h.readOnly {
+		return false, ErrReadOnly
+	}
+	got, ok := h.Get(host)
+	if !ok || sameIP(got, ip) {
+		return false, nil
+	}
+	err = h.Edit(host, ip)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// EditIfAbsent adds host with ip only if host has no entry, and reports
+// whether it was added. A host that already has an entry is left alone,
+// whatever its IP address, and the file is not written.
+func (h *HostsEdit) EditIfAbsent(host, ip string) (added bool, err error) {
+	if h.readOnly {
+		return false, ErrReadOnly
+	}
+	if h.Exists(host) {
+		return false, nil
+	}
+	err = h.Edit(host, ip)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
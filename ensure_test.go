@@ -0,0 +1,165 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+// 测试EnsureExists方法
+func TestEnsureExists(t *testing.T) {
+	hostsContent := "10.0.0.1 a.test\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	os.Chtimes(filePath, past, past)
+
+	hostsEdit, _ := New(filePath, false)
+	// 已存在相同的映射时不写入文件
+	err = hostsEdit.EnsureExists("a.test", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("EnsureExists(a.test, 10.0.0.1) error = %v", err)
+	}
+	if info, _ := os.Stat(filePath); !info.ModTime().Equal(past) {
+		t.Errorf("EnsureExists() of an existing mapping wrote the file")
+	}
+
+	err = hostsEdit.EnsureExists("a.test", "10.0.0.2")
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || !errors.Is(err, ErrConflict) || conflict.Got != "10.0.0.1" {
+		t.Errorf("EnsureExists(a.test, 10.0.0.2) error = %v; want a conflict with 10.0.0.1", err)
+	}
+
+	err = hostsEdit.EnsureExists("b.test", "10.0.0.2")
+	if err != nil {
+		t.Fatalf("EnsureExists(b.test, 10.0.0.2) error = %v", err)
+	}
+	want := "10.0.0.2 b.test\n10.0.0.1 a.test\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+}
+
+// 测试EnsureAbsent方法
+func TestEnsureAbsent(t *testing.T) {
+	hostsContent := "10.0.0.1 a.test b.test\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	os.Chtimes(filePath, past, past)
+
+	hostsEdit, _ := New(filePath, false)
+	// 主机不存在时不写入文件
+	err = hostsEdit.EnsureAbsent("missing.test")
+	if err != nil {
+		t.Fatalf("EnsureAbsent(missing.test) error = %v", err)
+	}
+	if info, _ := os.Stat(filePath); !info.ModTime().Equal(past) {
+		t.Errorf("EnsureAbsent() of a missing host wrote the file")
+	}
+
+	err = hostsEdit.EnsureAbsent("a.test")
+	if err != nil {
+		t.Fatalf("EnsureAbsent(a.test) error = %v", err)
+	}
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != "10.0.0.1 b.test\n" {
+		t.Errorf("saved content = %q; want %q", contentBytes, "10.0.0.1 b.test\n")
+	}
+}
+
+// 测试EditIfExists方法
+func TestEditIfExists(t *testing.T) {
+	hostsContent := "10.0.0.1 a.test\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	os.Chtimes(filePath, past, past)
+
+	hostsEdit, _ := New(filePath, false)
+	// 主机不存在或IP相同时不写入文件
+	for _, host := range []string{"missing.test", "a.test"} {
+		changed, err := hostsEdit.EditIfExists(host, "10.0.0.1")
+		if changed || err != nil {
+			t.Errorf("EditIfExists(%s, 10.0.0.1) = %v, %v; want false, nil", host, changed, err)
+		}
+	}
+	if info, _ := os.Stat(filePath); !info.ModTime().Equal(past) {
+		t.Errorf("EditIfExists() without a change wrote the file")
+	}
+	if hostsEdit.Exists("missing.test") {
+		t.Errorf("EditIfExists() added missing.test")
+	}
+
+	changed, err := hostsEdit.EditIfExists("a.test", "10.0.0.2")
+	if !changed || err != nil {
+		t.Fatalf("EditIfExists(a.test, 10.0.0.2) = %v, %v; want true, nil", changed, err)
+	}
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != "10.0.0.2 a.test\n" {
+		t.Errorf("saved content = %q; want %q", contentBytes, "10.0.0.2 a.test\n")
+	}
+}
+
+// 测试EditIfAbsent方法
+func TestEditIfAbsent(t *testing.T) {
+	hostsContent := "10.0.0.1 a.test\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	os.Chtimes(filePath, past, past)
+
+	hostsEdit, _ := New(filePath, false)
+	// 已存在的主机不被覆盖
+	added, err := hostsEdit.EditIfAbsent("a.test", "10.0.0.2")
+	if added || err != nil {
+		t.Errorf("EditIfAbsent(a.test, 10.0.0.2) = %v, %v; want false, nil", added, err)
+	}
+	if info, _ := os.Stat(filePath); !info.ModTime().Equal(past) {
+		t.Errorf("EditIfAbsent() of an existing host wrote the file")
+	}
+
+	added, err = hostsEdit.EditIfAbsent("b.test", "10.0.0.2")
+	if !added || err != nil {
+		t.Fatalf("EditIfAbsent(b.test, 10.0.0.2) = %v, %v; want true, nil", added, err)
+	}
+	want := "10.0.0.2 b.test\n10.0.0.1 a.test\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+}
@@ -0,0 +1,84 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrMalformedLine means a line is neither blank, a comment nor a valid
+	// "IP host..." entry.
+	ErrMalformedLine = errors.New("malformed line")
+	// ErrDuplicateHost means a host appears more than once among the entry
+	// lines, also twice on the same line.
+	ErrDuplicateHost = errors.New("duplicate host")
+	// ErrHostNotFound means a host has no active entry.
+	ErrHostNotFound = errors.New("host not found")
+	// ErrSectionNotFound means a file has no "# BEGIN <name>" marker for a
+	// section.
+	ErrSectionNotFound = errors.New("section not found")
+	// ErrReadOnly means a method that changes the hosts file was called on
+	// an instance created with WithReadOnly.
+	ErrReadOnly = errors.New("hosts file is read-only")
+	// ErrInvalidHostname means a host is not a valid host name, as checked
+	// by ValidateHostname.
+	ErrInvalidHostname = errors.New("invalid hostname")
+	// ErrImmutableFile means the hosts file could not be written because it
+	// has the immutable attribute, set with "chattr +i" on Linux. The
+	// error also wraps the original error of the write.
+	ErrImmutableFile = errors.New("hosts file is immutable")
+	// ErrIndexOutOfRange means a line index is not an index of Lines.
+	ErrIndexOutOfRange = errors.New("line index out of range")
+	// ErrNothingToUndo means Undo was called with no operation to reverse.
+	ErrNothingToUndo = errors.New("nothing to undo")
+	// ErrNothingToRedo means Redo was called with no operation to repeat.
+	ErrNothingToRedo = errors.New("nothing to redo")
+	// ErrHistoryChanged means the lines changed outside the operations
+	// recorded for Undo and Redo, so the history no longer applies.
+	ErrHistoryChanged = errors.New("lines changed outside the undo history")
+)
+
+// ParseError describes a problem found while parsing a hosts file.
+// Err is one of the Err* values of this package.
+//
+// Column and Length locate the offending part of the line, such as an
+// invalid IP address, a duplicate host or a character host names cannot
+// have, in the line as written in the file, leading whitespace included,
+// so that editors can highlight it. They count bytes, not runes.
+type ParseError struct {
+	Line   int    // 1-based line number, 0 if unknown
+	Column int    // 1-based byte offset in the line, 0 if unknown
+	Length int    // length in bytes of the offending part of the line
+	Text   string // the offending text
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	if e.Line > 0 && e.Column > 0 {
+		return fmt.Sprintf("line %d, column %d: %v: %q", e.Line, e.Column, e.Err, e.Text)
+	}
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %v: %q", e.Line, e.Err, e.Text)
+	}
+	return fmt.Sprintf("%v: %q", e.Err, e.Text)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
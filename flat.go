@@ -0,0 +1,85 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"errors"
+	"strings"
+)
+
+// FlatLines returns the active entries with one host each, as "IP host"
+// strings in file order, so a line with several hosts gives one string per
+// host. This is the format expected by tools that take one mapping per line.
+func (h *HostsEdit) FlatLines() []string {
+	flat := []string{}
+	for _, line := range h.Lines {
+		if !isActive(line) {
+			continue
+		}
+		for _, host := range line.hosts {
+			flat = append(flat, line.IP+" "+host)
+		}
+	}
+	return flat
+}
+
+// ParseFlatLines replaces the lines of h with the entries in lines, in the
+// format returned by FlatLines, each giving one line. Blank strings are
+// skipped. A string that is not an IP address followed by a single host is
+// a *ParseError wrapping ErrMalformedLine, with the 1-based index of the
+// string as line number. If the instance was created with isParse, the
+// result must pass the same checks as New. Nothing is changed on error.
+// The file is not saved; call Save to write the new lines.
+func (h *HostsEdit) ParseFlatLines(lines []string) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+
+	var parsed []*Line
+	for i, s := range lines {
+		line, err := ParseLine(s)
+		if err != nil {
+			var parseErr *ParseError
+			if errors.As(err, &parseErr) {
+				parseErr.Line = i + 1
+			}
+			return err
+		}
+		if line.isBlank() {
+			continue
+		}
+		if line.IsComment || len(line.hosts) != 1 || len(line.Extra) > 0 || line.InlineComment != "" {
+			return &ParseError{Line: i + 1, Text: strings.TrimSpace(s), Err: ErrMalformedLine}
+		}
+		line.num = i + 1
+		parsed = append(parsed, line)
+	}
+
+	if h.isParse {
+		if err := parse(parsed); err != nil {
+			return err
+		}
+	}
+	for _, line := range parsed {
+		if h.lowercaseHosts {
+			line.hosts[0] = lowerASCII(line.hosts[0])
+		}
+		line.touch()
+	}
+	h.Lines = parsed
+	return nil
+}
@@ -0,0 +1,103 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// 测试FlatLines方法
+func TestFlatLines(t *testing.T) {
+	hostsContent := "# comment\n127.0.0.1 localhost myapp.local # dev\n# 10.0.0.1 disabled.test\n::1 localhost\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	want := []string{"127.0.0.1 localhost", "127.0.0.1 myapp.local", "::1 localhost"}
+	if got := hostsEdit.FlatLines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("FlatLines() = %v; want %v", got, want)
+	}
+}
+
+// 测试ParseFlatLines方法
+func TestParseFlatLines(t *testing.T) {
+	filePath, err := createTestHostsFile("10.0.0.9 old.test\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	flat := []string{"127.0.0.1 localhost", "", "127.0.0.1\tmyapp.local", "::1 localhost"}
+	err = hostsEdit.ParseFlatLines(flat)
+	if err != nil {
+		t.Fatalf("ParseFlatLines() error = %v", err)
+	}
+	if got := hostsEdit.FlatLines(); !reflect.DeepEqual(got, []string{"127.0.0.1 localhost", "127.0.0.1 myapp.local", "::1 localhost"}) {
+		t.Errorf("FlatLines() after ParseFlatLines() = %v", got)
+	}
+	// 只修改内存中的内容，保存后才写入文件
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != "10.0.0.9 old.test\n" {
+		t.Errorf("ParseFlatLines() wrote the file: %q", contentBytes)
+	}
+	err = hostsEdit.Save()
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	contentBytes, _ = os.ReadFile(filePath)
+	if string(contentBytes) != "127.0.0.1 localhost\n127.0.0.1 myapp.local\n::1 localhost\n" {
+		t.Errorf("saved content = %q", contentBytes)
+	}
+
+	tests := []struct {
+		lines []string
+		line  int
+		err   error
+	}{
+		{[]string{"127.0.0.1 a.test", "127.0.0.1 b.test c.test"}, 2, ErrMalformedLine},
+		{[]string{"# 127.0.0.1 a.test"}, 1, ErrMalformedLine},
+		{[]string{"a.test:127.0.0.1"}, 1, ErrMalformedLine},
+	}
+	for _, tt := range tests {
+		err = hostsEdit.ParseFlatLines(tt.lines)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) || parseErr.Line != tt.line || !errors.Is(err, tt.err) {
+			t.Errorf("ParseFlatLines(%q) error = %v; want %v on line %d", tt.lines, err, tt.err, tt.line)
+		}
+	}
+	if hostsEdit.Len() != 3 {
+		t.Errorf("Len() = %d after failed ParseFlatLines(); want 3", hostsEdit.Len())
+	}
+
+	// 严格模式下与New的检查相同
+	os.WriteFile(filePath, []byte("10.0.0.9 old.test\n"), 0o644)
+	hostsEdit, err = New(filePath, true)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	err = hostsEdit.ParseFlatLines([]string{"127.0.0.1 a.test", "::1 A.test"})
+	if !errors.Is(err, ErrDuplicateHost) {
+		t.Errorf("ParseFlatLines(duplicate host) error = %v; want ErrDuplicateHost", err)
+	}
+}
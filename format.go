@@ -0,0 +1,167 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format is a file format understood by Import and Export.
+type Format int
+
+const (
+	// FormatHostsFile is the hosts file format itself.
+	FormatHostsFile Format = iota
+	// FormatJSON is a JSON array of YAMLEntry objects.
+	FormatJSON
+	// FormatCSV is CSV with the header ip,hosts,comment,inline_comment and
+	// one record per YAMLEntry, the hosts separated by spaces. Import also
+	// accepts CSV without the inline_comment column.
+	FormatCSV
+	// FormatYAML is the format of ExportYAML and ImportYAML.
+	FormatYAML
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatHostsFile:
+		return "hosts"
+	case FormatJSON:
+		return "json"
+	case FormatCSV:
+		return "csv"
+	case FormatYAML:
+		return "yaml"
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// csvHeader is the header record of FormatCSV.
+var csvHeader = []string{"ip", "hosts", "comment", "inline_comment"}
+
+// Export writes the hosts file to w in the given format. Except for
+// FormatHostsFile, lines that are neither comments nor valid entries are not
+// exported.
+func (h *HostsEdit) Export(w io.Writer, format Format) error {
+	switch format {
+	case FormatHostsFile:
+		_, err := w.Write(h.render())
+		return err
+	case FormatJSON:
+		entries := h.entries()
+		if entries == nil {
+			entries = []YAMLEntry{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		cw.Write(csvHeader)
+		for _, e := range h.entries() {
+			cw.Write([]string{e.IP, strings.Join(e.Hosts, " "), e.Comment, e.InlineComment})
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatYAML:
+		return h.ExportYAML(w)
+	}
+	return fmt.Errorf("unsupported format %v", format)
+}
+
+// Import replaces the content of the hosts file with the content read from
+// r in the given format and saves it. FormatHostsFile is parsed the same way
+// New does; the other formats are checked as ImportYAML describes.
+func (h *HostsEdit) Import(r io.Reader, format Format) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	op := operation{name: "Import"}
+	switch format {
+	case FormatHostsFile:
+		_, err := h.load(r)
+		if err != nil {
+			return err
+		}
+		if h.lowercaseHosts {
+			lowercaseHosts(h.Lines)
+		}
+		return h.saveToFile(op)
+	case FormatJSON:
+		var entries []YAMLEntry
+		dec := json.NewDecoder(r)
+		if h.isParse {
+			dec.DisallowUnknownFields()
+		}
+		err := dec.Decode(&entries)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		return h.importEntries(entries, op)
+	case FormatCSV:
+		entries, err := readCSVEntries(r)
+		if err != nil {
+			return err
+		}
+		return h.importEntries(entries, op)
+	case FormatYAML:
+		return h.ImportYAML(r)
+	}
+	return fmt.Errorf("unsupported format %v", format)
+}
+
+// readCSVEntries reads the records of FormatCSV.
+func readCSVEntries(r io.Reader) ([]YAMLEntry, error) {
+	// 所有记录的字段数必须和表头相同
+	cr := csv.NewReader(r)
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	header := records[0]
+	// 兼容没有inline_comment列的旧文件
+	if n := len(header); n != len(csvHeader) && n != len(csvHeader)-1 {
+		return nil, fmt.Errorf("csv header must be %s", strings.Join(csvHeader, ","))
+	}
+	for i, name := range header {
+		if strings.TrimSpace(strings.ToLower(name)) != csvHeader[i] {
+			return nil, fmt.Errorf("csv header must be %s", strings.Join(csvHeader, ","))
+		}
+	}
+
+	var entries []YAMLEntry
+	for _, record := range records[1:] {
+		entry := YAMLEntry{
+			IP:      strings.TrimSpace(record[0]),
+			Hosts:   strings.Fields(record[1]),
+			Comment: record[2],
+		}
+		if len(record) > 3 {
+			entry.InlineComment = record[3]
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
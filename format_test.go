@@ -0,0 +1,129 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	hostedit "github.com/Deng-Xian-Sheng/go-hosts-edit-library"
+	"github.com/Deng-Xian-Sheng/go-hosts-edit-library/hostedittest"
+)
+
+// 测试Export和Import支持的所有格式
+func TestExportImport(t *testing.T) {
+	hostsContent := "# loopback\n127.0.0.1 localhost myapp.local\n::1 ipv6host # note\n# trailing, \"quoted\"\n"
+	filePath := hostedittest.WriteTempHosts(t, hostsContent)
+
+	hostsEdit, _ := hostedit.New(filePath, false)
+
+	tests := []struct {
+		format hostedit.Format
+		want   string
+	}{
+		{hostedit.FormatHostsFile, hostsContent},
+		{hostedit.FormatJSON, `[
+  {
+    "ip": "127.0.0.1",
+    "hosts": [
+      "localhost",
+      "myapp.local"
+    ],
+    "comment": "loopback"
+  },
+  {
+    "ip": "::1",
+    "hosts": [
+      "ipv6host"
+    ],
+    "inline_comment": "note"
+  },
+  {
+    "comment": "trailing, \"quoted\""
+  }
+]
+`},
+		{hostedit.FormatCSV, "ip,hosts,comment,inline_comment\n127.0.0.1,localhost myapp.local,loopback,\n::1,ipv6host,,note\n,,\"trailing, \"\"quoted\"\"\",\n"},
+		{hostedit.FormatYAML, "- ip: 127.0.0.1\n  hosts: [localhost, myapp.local]\n  comment: loopback\n- ip: ::1\n  hosts: [ipv6host]\n  inline_comment: note\n- comment: trailing, \"quoted\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			err := hostsEdit.Export(&buf, tt.format)
+			if err != nil {
+				t.Fatalf("Export(%v) error = %v", tt.format, err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Export(%v) differs (-want +got):\n%s", tt.format, hostedittest.Diff(tt.want, buf.String()))
+			}
+
+			targetPath := hostedittest.WriteTempHosts(t, "10.0.0.1 old.test\n")
+			target, _ := hostedit.New(targetPath, true)
+			err = target.Import(strings.NewReader(buf.String()), tt.format)
+			if err != nil {
+				t.Fatalf("Import(%v) error = %v", tt.format, err)
+			}
+			hostedittest.AssertContent(t, targetPath, hostsContent)
+		})
+	}
+
+	// 没有inline_comment列的CSV仍然可以导入
+	err := hostsEdit.Import(strings.NewReader("ip,hosts,comment\n10.0.0.1,a.test,\n"), hostedit.FormatCSV)
+	if err != nil {
+		t.Fatalf("Import() of CSV without inline_comment error = %v", err)
+	}
+	hostedittest.AssertContent(t, filePath, "10.0.0.1 a.test\n")
+
+	if err := hostsEdit.Import(strings.NewReader("address,names\n"), hostedit.FormatCSV); err == nil {
+		t.Errorf("Import() of CSV with a wrong header should fail")
+	}
+}
+
+// 测试导入时拒绝无效的主机
+func TestImportInvalidHost(t *testing.T) {
+	hostsContent := "127.0.0.1 localhost\n"
+	filePath := hostedittest.WriteTempHosts(t, hostsContent)
+
+	hostsEdit, _ := hostedit.New(filePath, false)
+	for _, host := range []string{"a!b", "-foo"} {
+		docs := map[hostedit.Format]string{
+			hostedit.FormatYAML: "- ip: 10.0.0.1\n  hosts: [ok.test]\n- ip: 10.0.0.2\n  hosts: [\"" + host + "\"]\n",
+			hostedit.FormatJSON: `[{"ip": "10.0.0.1", "hosts": ["ok.test"]}, {"ip": "10.0.0.2", "hosts": ["` + host + `"]}]`,
+			hostedit.FormatCSV:  "ip,hosts,comment\n10.0.0.1,ok.test,\n10.0.0.2," + host + ",\n",
+		}
+		for format, doc := range docs {
+			err := hostsEdit.Import(strings.NewReader(doc), format)
+			if err == nil || !strings.Contains(err.Error(), "entry 1") || !strings.Contains(err.Error(), host) {
+				t.Errorf("Import(%v) with host %q error = %v; want an error naming entry 1", format, host, err)
+			}
+		}
+	}
+	hostedittest.AssertContent(t, filePath, hostsContent)
+}
+
+// 测试WithLowercaseHosts对导入的hosts文件同样生效
+func TestImportHostsFileLowercase(t *testing.T) {
+	filePath := hostedittest.WriteTempHosts(t, "127.0.0.1 localhost\n")
+
+	hostsEdit, _ := hostedit.New(filePath, false, hostedit.WithLowercaseHosts())
+	err := hostsEdit.Import(strings.NewReader("# Comment\n10.0.0.1 API.Test other\n# 10.0.0.2 Disabled.Test\n"), hostedit.FormatHostsFile)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	hostedittest.AssertContent(t, filePath, "# Comment\n10.0.0.1 api.test other\n# 10.0.0.2 disabled.test\n")
+}
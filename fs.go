@@ -0,0 +1,101 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import "os"
+
+// fileSystem is the file access used to write the hosts file, replaced in
+// tests to simulate failures.
+type fileSystem interface {
+	Create(name string) (*os.File, error)
+	OpenFile(name string, flag int, perm os.FileMode) (*os.File, error)
+	CreateTemp(dir, pattern string) (*os.File, error)
+	Rename(oldpath, newpath string) error
+	Remove(name string) error
+	Stat(name string) (os.FileInfo, error)
+}
+
+// osFileSystem is the fileSystem of the operating system.
+type osFileSystem struct{}
+
+func (osFileSystem) Create(name string) (*os.File, error) {
+	return os.Create(name)
+}
+
+func (osFileSystem) OpenFile(name string, flag int, perm os.FileMode) (*os.File, error) {
+	return os.OpenFile(name, flag, perm)
+}
+
+func (osFileSystem) CreateTemp(dir, pattern string) (*os.File, error) {
+	return os.CreateTemp(dir, pattern)
+}
+
+func (osFileSystem) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+func (osFileSystem) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (osFileSystem) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(name)
+}
+
+// fileSystem returns the fileSystem of h, with the retries of
+// WithWriteRetry.
+func (h *HostsEdit) fileSystem() fileSystem {
+	if h.fs == nil {
+		return h.retryFileSystem(osFileSystem{})
+	}
+	return h.retryFileSystem(h.fs)
+}
+
+// WriteStrategy is how a save wrote the hosts file.
+type WriteStrategy int
+
+const (
+	// WriteInPlace truncates the file and writes the whole content to it,
+	// when it cannot be replaced.
+	WriteInPlace WriteStrategy = iota
+	// WriteAppend writes only the new lines at the end of the file.
+	WriteAppend
+	// WriteAtomic writes a temporary file and renames it over the file.
+	WriteAtomic
+	// WriteStore saves the content to the store given to NewWithStore.
+	WriteStore
+)
+
+func (s WriteStrategy) String() string {
+	switch s {
+	case WriteInPlace:
+		return "in-place"
+	case WriteAppend:
+		return "append"
+	case WriteAtomic:
+		return "atomic"
+	case WriteStore:
+		return "store"
+	}
+	return "unknown"
+}
+
+// LastWriteStrategy returns how the last successful save wrote the file.
+// It is WriteInPlace if nothing has been saved yet.
+func (h *HostsEdit) LastWriteStrategy() WriteStrategy {
+	return h.lastWrite
+}
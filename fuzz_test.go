@@ -0,0 +1,122 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fuzzSeeds 返回模糊测试的初始语料：所有样本文件和一些边界情况
+func fuzzSeeds(f *testing.F) []string {
+	fixtures, err := filepath.Glob("testdata/roundtrip/*.hosts")
+	if err != nil {
+		f.Fatal(err)
+	}
+	var seeds []string
+	for _, fixture := range fixtures {
+		content, err := os.ReadFile(fixture)
+		if err != nil {
+			f.Fatal(err)
+		}
+		seeds = append(seeds, string(content))
+	}
+	return append(seeds,
+		"#",
+		"127.0.0.1 "+strings.Repeat("a", 70000),
+		"127.0.0.1 \xff\xfe invalid\n\xc3\x28 x\n",
+		"fe80::1%eth0 link-local\n",
+		"##\n# #\n#127.0.0.1 a a\n",
+		"127.0.0.1 a#b # c #\n# 10.0.0.1 x #\n",
+		"127.0.0.1 a b=1 c # note\n127.0.0.2 =x\n",
+		"\xef\xbb\xbf\r\n\r\n127.0.0.1\tlocalhost\r",
+	)
+}
+
+// entryFields 返回行的语义内容，用于比较两次解析的结果
+func entryFields(lines []*Line) [][]string {
+	var fields [][]string
+	for _, line := range lines {
+		if line.isBlank() {
+			continue
+		}
+		comment := ""
+		if line.IsComment {
+			comment = "#"
+		}
+		entry := append([]string{comment, line.UndefinedRowsRawStr, line.IP, line.InlineComment}, line.hosts...)
+		fields = append(fields, append(append(entry, "|"), line.Extra...))
+	}
+	return fields
+}
+
+// 模糊测试解析：不会崩溃，解析结果可以保存，重新解析保存的内容得到相同的条目
+func FuzzNew(f *testing.F) {
+	for _, seed := range fuzzSeeds(f) {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, content string) {
+		h, err := NewFromReader(strings.NewReader(content), false)
+		if err != nil {
+			t.Fatalf("NewFromReader() error = %v", err)
+		}
+		saved := h.render()
+		reparsed, err := NewFromReader(bytes.NewReader(saved), false)
+		if err != nil {
+			t.Fatalf("NewFromReader() of saved content error = %v", err)
+		}
+		if got, want := entryFields(reparsed.Lines), entryFields(h.Lines); !reflect.DeepEqual(got, want) {
+			t.Errorf("saved content %q parses to %q; want %q", saved, got, want)
+		}
+
+		// 保留格式模式下原样写回
+		preserved, err := NewFromReader(strings.NewReader(content), false, WithPreserveFormatting())
+		if err != nil {
+			t.Fatalf("NewFromReader() with WithPreserveFormatting error = %v", err)
+		}
+		if got := preserved.render(); string(got) != content {
+			t.Errorf("preserved content = %q; want %q", got, content)
+		}
+	})
+}
+
+// 模糊测试单行解析：格式化后重新解析得到相同的行
+func FuzzParseLine(f *testing.F) {
+	for _, seed := range fuzzSeeds(f) {
+		for _, s := range strings.Split(seed, "\n") {
+			f.Add(s)
+		}
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		if strings.ContainsAny(s, "\n") {
+			t.Skip()
+		}
+		line, err := ParseLine(s)
+		if line == nil {
+			t.Fatalf("ParseLine(%q) returned a nil line with error %v", s, err)
+		}
+		formatted := FormatLine(line)
+		reparsed, _ := ParseLine(formatted)
+		if got, want := entryFields([]*Line{reparsed}), entryFields([]*Line{line}); !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseLine(%q) = %q, reparsed from %q as %q", s, want, formatted, got)
+		}
+	})
+}
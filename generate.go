@@ -0,0 +1,134 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GenerateSort is the order of the entries written by GenerateEtcHosts.
+type GenerateSort int
+
+const (
+	// SortByHost writes one line per host, ordered by host.
+	SortByHost GenerateSort = iota
+	// SortByIP writes one line per IP address with its hosts in order,
+	// ordered by address, IPv4 before IPv6.
+	SortByIP
+)
+
+// GenerateOptions configures GenerateEtcHosts.
+type GenerateOptions struct {
+	// Preamble is written at the top of the file as comment lines, each
+	// element without the leading "#", like the lines of WithHeader.
+	Preamble []string
+	// Sort is the order of the entries.
+	Sort GenerateSort
+	// LoopbackDefaults writes the conventional loopback entries, as
+	// EnsureLocalhostDefaults and EnsureIPv6Defaults add them, before the
+	// other entries. A default host that entries also maps is left out.
+	LoopbackDefaults bool
+	// Render configures the entry lines as for FormatLine.
+	Render []RenderOption
+}
+
+// GenerateEtcHosts returns the content of a hosts file that maps every host
+// of entries to its IP address, without reading or writing any file. Every
+// IP address must be valid and every host must pass ValidateHostname; the
+// first invalid entry, in host order, is returned as an error. Hosts that
+// differ only in case are an error too, as they are the same host.
+func GenerateEtcHosts(entries map[string]string, opts GenerateOptions) (string, error) {
+	hosts := make([]string, 0, len(entries))
+	for host := range entries {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	seen := make(map[string]struct{}, len(hosts))
+	for _, host := range hosts {
+		if err := ValidateHostname(host); err != nil {
+			return "", err
+		}
+		if !isAddr(entries[host]) {
+			return "", fmt.Errorf("invalid ip %q for host %q", entries[host], host)
+		}
+		key := lowerASCII(host)
+		if _, ok := seen[key]; ok {
+			return "", fmt.Errorf("%w: %q", ErrDuplicateHost, host)
+		}
+		seen[key] = struct{}{}
+	}
+
+	var lines []*Line
+	for _, text := range opts.Preamble {
+		lines = append(lines, &Line{IsComment: true, UndefinedRowsRawStr: strings.TrimSpace(text)})
+	}
+	if opts.LoopbackDefaults {
+		defaults := append([]struct {
+			ip    string
+			hosts []string
+		}{{"127.0.0.1", []string{"localhost"}}}, ipv6Defaults...)
+		for _, d := range defaults {
+			var missing []string
+			for _, host := range d.hosts {
+				if _, ok := seen[host]; !ok {
+					missing = append(missing, host)
+				}
+			}
+			if len(missing) > 0 {
+				lines = append(lines, NewLine(d.ip, missing...))
+			}
+		}
+	}
+
+	switch opts.Sort {
+	case SortByIP:
+		byIP := make(map[string]*Line)
+		var grouped []*Line
+		for _, host := range hosts {
+			ip := entries[host]
+			line, ok := byIP[ip]
+			if !ok {
+				line = NewLine(ip)
+				byIP[ip] = line
+				grouped = append(grouped, line)
+			}
+			line.appendHost(host)
+		}
+		sort.SliceStable(grouped, func(i, j int) bool {
+			return lessLine(grouped[i], grouped[j])
+		})
+		lines = append(lines, grouped...)
+	default:
+		for _, host := range hosts {
+			lines = append(lines, NewLine(entries[host], host))
+		}
+	}
+
+	var c renderConfig
+	for _, opt := range opts.Render {
+		opt(&c)
+	}
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(formatLine(line, c))
+		b.WriteByte('\n')
+	}
+	return b.String(), nil
+}
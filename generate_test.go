@@ -0,0 +1,78 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// 测试GenerateEtcHosts函数
+func TestGenerateEtcHosts(t *testing.T) {
+	entries := map[string]string{
+		"web.test":  "10.0.0.2",
+		"api.test":  "10.0.0.2",
+		"db.test":   "10.0.0.1",
+		"v6.test":   "fd00::1",
+		"localhost": "127.0.0.1",
+	}
+
+	got, err := GenerateEtcHosts(entries, GenerateOptions{})
+	if err != nil {
+		t.Fatalf("GenerateEtcHosts() error = %v", err)
+	}
+	want := "10.0.0.2 api.test\n10.0.0.1 db.test\n127.0.0.1 localhost\nfd00::1 v6.test\n10.0.0.2 web.test\n"
+	if got != want {
+		t.Errorf("GenerateEtcHosts() = %q; want %q", got, want)
+	}
+
+	got, err = GenerateEtcHosts(entries, GenerateOptions{
+		Preamble:         []string{"generated", ""},
+		Sort:             SortByIP,
+		LoopbackDefaults: true,
+		Render:           []RenderOption{WithIPColumnWidth(10)},
+	})
+	if err != nil {
+		t.Fatalf("GenerateEtcHosts(SortByIP) error = %v", err)
+	}
+	want = "# generated\n#\n" +
+		"::1       ip6-localhost ip6-loopback\nff02::1   ip6-allnodes\nff02::2   ip6-allrouters\n" +
+		"10.0.0.1  db.test\n10.0.0.2  api.test web.test\n127.0.0.1 localhost\nfd00::1   v6.test\n"
+	if got != want {
+		t.Errorf("GenerateEtcHosts(SortByIP) = %q; want %q", got, want)
+	}
+
+	// 生成的内容可以被解析
+	hostsEdit, err := NewFromReader(strings.NewReader(got), true)
+	if err != nil {
+		t.Fatalf("NewFromReader() error = %v", err)
+	}
+	if ip, _ := hostsEdit.Get("web.test"); ip != "10.0.0.2" {
+		t.Errorf("Get(web.test) = %q; want 10.0.0.2", ip)
+	}
+
+	if _, err := GenerateEtcHosts(map[string]string{"bad_host": "10.0.0.1"}, GenerateOptions{}); !errors.Is(err, ErrInvalidHostname) {
+		t.Errorf("GenerateEtcHosts(bad_host) error = %v; want ErrInvalidHostname", err)
+	}
+	if _, err := GenerateEtcHosts(map[string]string{"a.test": "10.0.0"}, GenerateOptions{}); err == nil {
+		t.Errorf("GenerateEtcHosts(invalid ip) succeeded; want error")
+	}
+	if _, err := GenerateEtcHosts(map[string]string{"a.test": "10.0.0.1", "A.test": "10.0.0.2"}, GenerateOptions{}); !errors.Is(err, ErrDuplicateHost) {
+		t.Errorf("GenerateEtcHosts(A.test, a.test) error = %v; want ErrDuplicateHost", err)
+	}
+}
@@ -0,0 +1,199 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"time"
+)
+
+// gobVersion is the version of the encoding written by GobEncode.
+const gobVersion = 1
+
+// gobHostsEdit is the encoded form of a HostsEdit.
+type gobHostsEdit struct {
+	Version  int
+	FilePath string
+	Lines    []gobLine
+
+	IsParse         bool
+	Preserve        bool
+	BOM             bool
+	EncodingSet     bool
+	EOL             string
+	InsertPolicy    InsertPolicy
+	ReuseDisabled   bool
+	Indent          string
+	Header          []string
+	MaxHostsPerLine int
+	CreateMode      os.FileMode
+	AutoNormalize   bool
+	ReadOnly        bool
+	StrictHostnames bool
+	Fsync           bool
+	FsyncSet        bool
+	LowercaseHosts  bool
+	AnnotateTool    string
+	WriteAttempts   int
+	WriteDelay      time.Duration
+	WSL             bool
+	HTTPTimeout     time.Duration
+	Separator       string
+	IPWidth         int
+	MachineHostname bool
+	UndoDepth       int
+	HistorySize     int
+}
+
+// gobLine is the encoded form of a Line.
+type gobLine struct {
+	IsComment           bool
+	UndefinedRowsRawStr string
+	IP                  string
+	IsDelete            bool
+	Hosts               []string
+	Extra               []string
+	InlineComment       string
+	Dup                 bool
+	Indent              string
+	Num                 int
+	Raw                 string
+	EOL                 string
+	Verbatim            bool
+	Dirty               bool
+}
+
+// GobEncode implements gob.GobEncoder. The encoding holds FilePath, the
+// lines with everything needed to write them back as they were, and the
+// options that are plain values. Options holding functions or interfaces,
+// such as a Store, a logger, a file system, hooks and a clock, are not
+// encoded.
+func (h *HostsEdit) GobEncode() ([]byte, error) {
+	g := gobHostsEdit{
+		Version:         gobVersion,
+		FilePath:        h.FilePath,
+		Lines:           make([]gobLine, len(h.Lines)),
+		IsParse:         h.isParse,
+		Preserve:        h.preserve,
+		BOM:             h.bom,
+		EncodingSet:     h.encodingSet,
+		EOL:             h.eol,
+		InsertPolicy:    h.insertPolicy,
+		ReuseDisabled:   h.reuseDisabled,
+		Indent:          h.indent,
+		Header:          h.header,
+		MaxHostsPerLine: h.maxHostsPerLine,
+		CreateMode:      h.createMode,
+		AutoNormalize:   h.autoNormalize,
+		ReadOnly:        h.readOnly,
+		StrictHostnames: h.strictHostnames,
+		Fsync:           h.fsync,
+		FsyncSet:        h.fsyncSet,
+		LowercaseHosts:  h.lowercaseHosts,
+		AnnotateTool:    h.annotateTool,
+		WriteAttempts:   h.writeAttempts,
+		WriteDelay:      h.writeDelay,
+		WSL:             h.wsl,
+		HTTPTimeout:     h.httpTimeout,
+		Separator:       h.renderConfig.separator,
+		IPWidth:         h.renderConfig.ipWidth,
+		MachineHostname: h.machineHostname,
+		UndoDepth:       h.undoDepth,
+		HistorySize:     h.historySize,
+	}
+	for i, line := range h.Lines {
+		g.Lines[i] = gobLine{
+			IsComment:           line.IsComment,
+			UndefinedRowsRawStr: line.UndefinedRowsRawStr,
+			IP:                  line.IP,
+			IsDelete:            line.IsDelete,
+			Hosts:               line.hosts,
+			Extra:               line.Extra,
+			InlineComment:       line.InlineComment,
+			Dup:                 line.dup,
+			Indent:              line.indent,
+			Num:                 line.num,
+			Raw:                 line.raw,
+			EOL:                 line.eol,
+			Verbatim:            line.verbatim,
+			Dirty:               line.dirty,
+		}
+	}
+
+	var b bytes.Buffer
+	err := gob.NewEncoder(&b).Encode(g)
+	if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// GobDecode implements gob.GobDecoder. It replaces FilePath, the lines and
+// the options encoded by GobEncode, and keeps the other options of h, so a
+// decoded instance can be given a Store or hooks before it is decoded. The
+// next save rewrites the whole file.
+func (h *HostsEdit) GobDecode(data []byte) error {
+	var g gobHostsEdit
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&g)
+	if err != nil {
+		return err
+	}
+	if g.Version != gobVersion {
+		return fmt.Errorf("unsupported gob encoding version %d", g.Version)
+	}
+
+	lines := make([]*Line, len(g.Lines))
+	for i, gl := range g.Lines {
+		line := &Line{
+			IsComment:           gl.IsComment,
+			UndefinedRowsRawStr: gl.UndefinedRowsRawStr,
+			IsDelete:            gl.IsDelete,
+			Extra:               gl.Extra,
+			InlineComment:       gl.InlineComment,
+			indent:              gl.Indent,
+			num:                 gl.Num,
+			raw:                 gl.Raw,
+			eol:                 gl.EOL,
+			verbatim:            gl.Verbatim,
+			dirty:               gl.Dirty,
+		}
+		line.setIP(gl.IP)
+		for _, host := range gl.Hosts {
+			line.appendHost(host)
+		}
+		line.dup = gl.Dup
+		lines[i] = line
+	}
+
+	h.FilePath, h.Lines = g.FilePath, lines
+	h.isParse, h.preserve, h.bom, h.encodingSet, h.eol = g.IsParse, g.Preserve, g.BOM, g.EncodingSet, g.EOL
+	h.insertPolicy, h.reuseDisabled, h.indent, h.header = g.InsertPolicy, g.ReuseDisabled, g.Indent, g.Header
+	h.maxHostsPerLine, h.createMode, h.autoNormalize, h.readOnly = g.MaxHostsPerLine, g.CreateMode, g.AutoNormalize, g.ReadOnly
+	h.strictHostnames, h.fsync, h.fsyncSet, h.lowercaseHosts = g.StrictHostnames, g.Fsync, g.FsyncSet, g.LowercaseHosts
+	h.annotateTool, h.writeAttempts, h.writeDelay = g.AnnotateTool, g.WriteAttempts, g.WriteDelay
+	h.wsl, h.httpTimeout, h.machineHostname = g.WSL, g.HTTPTimeout, g.MachineHostname
+	h.undoDepth, h.historySize = g.UndoDepth, g.HistorySize
+	h.renderConfig = renderConfig{separator: g.Separator, ipWidth: g.IPWidth}
+	// 解码后的内容与磁盘上的文件无关，不能走追加的方式
+	h.synced, h.syncedSize = nil, 0
+	h.resetAudit()
+	h.clearHistory()
+	return nil
+}
@@ -0,0 +1,82 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"bytes"
+	"encoding/gob"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// 测试gob编码和解码
+func TestGob(t *testing.T) {
+	hostsContent := "# hosts\r\n\r\n  127.0.0.1   localhost\r\n10.0.0.1 a.test a.test opt=1 # note\r\n# 10.0.0.2 disabled.test\r\ngarbage"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false, WithPreserveFormatting(), WithInsertPolicy(InsertAppend), WithHeader([]string{"Managed by myapp"}))
+
+	var b bytes.Buffer
+	err = gob.NewEncoder(&b).Encode(hostsEdit)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	var decoded HostsEdit
+	err = gob.NewDecoder(&b).Decode(&decoded)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if decoded.FilePath != filePath || decoded.Len() != hostsEdit.Len() {
+		t.Errorf("decoded FilePath = %q, Len() = %d; want %q, %d", decoded.FilePath, decoded.Len(), filePath, hostsEdit.Len())
+	}
+	if got := string(decoded.render()); got != hostsContent {
+		t.Errorf("decoded content = %q; want %q", got, hostsContent)
+	}
+	if !reflect.DeepEqual(decoded.Lines[3].Extra, []string{"opt=1"}) || decoded.Lines[3].InlineComment != "note" {
+		t.Errorf("decoded line = %+v", decoded.Lines[3])
+	}
+	if !decoded.Contains("a.test", "10.0.0.1") || decoded.insertPolicy != InsertAppend || !reflect.DeepEqual(decoded.header, hostsEdit.header) {
+		t.Errorf("decoded instance lost entries or options")
+	}
+
+	// 解码后的实例与原实例的修改结果相同
+	err = decoded.Edit("b.test", "10.0.0.3")
+	if err != nil {
+		t.Fatalf("Edit() on the decoded instance failed with error: %v", err)
+	}
+	decodedContent, _ := os.ReadFile(filePath)
+	os.WriteFile(filePath, []byte(hostsContent), 0o644)
+	err = hostsEdit.Edit("b.test", "10.0.0.3")
+	if err != nil {
+		t.Fatalf("Edit() failed with error: %v", err)
+	}
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(decodedContent) != string(contentBytes) {
+		t.Errorf("content saved by the decoded instance = %q; want %q", decodedContent, contentBytes)
+	}
+
+	err = decoded.GobDecode([]byte("not gob"))
+	if err == nil {
+		t.Errorf("GobDecode(invalid) succeeded")
+	}
+}
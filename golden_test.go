@@ -0,0 +1,69 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit_test
+
+import (
+	"os"
+	"testing"
+
+	hostedit "github.com/Deng-Xian-Sheng/go-hosts-edit-library"
+	"github.com/Deng-Xian-Sheng/go-hosts-edit-library/hostedittest"
+)
+
+// 使用hostedittest对比编辑结果和golden文件
+func TestEditGolden(t *testing.T) {
+	content, err := os.ReadFile("testdata/golden/edit.hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := hostedittest.WriteTempHosts(t, string(content))
+
+	h, err := hostedit.New(path, false, hostedit.WithPreserveFormatting(), hostedit.WithInsertPolicy(hostedit.InsertAppend))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := h.Edit("api.test", "10.0.0.2"); err != nil {
+		t.Fatalf("Edit(api.test, 10.0.0.2) failed with error: %v", err)
+	}
+	if err := h.Edit("db.test", "10.0.0.3"); err != nil {
+		t.Fatalf("Edit(db.test, 10.0.0.3) failed with error: %v", err)
+	}
+
+	hostedittest.AssertFileEquals(t, path, "testdata/golden/edit.golden")
+}
+
+// 测试保留格式模式下修改过的行保留缩进
+func TestPreserveIndentationGolden(t *testing.T) {
+	content, err := os.ReadFile("testdata/roundtrip/indented.hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := hostedittest.WriteTempHosts(t, string(content))
+
+	h, err := hostedit.New(path, false, hostedit.WithPreserveFormatting(), hostedit.WithIndent("  "), hostedit.WithInsertPolicy(hostedit.InsertAppend))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := h.Edit("a.test", "10.0.0.9"); err != nil {
+		t.Fatalf("Edit(a.test, 10.0.0.9) failed with error: %v", err)
+	}
+	if err := h.Edit("www.b.test", "10.0.0.8"); err != nil {
+		t.Fatalf("Edit(www.b.test, 10.0.0.8) failed with error: %v", err)
+	}
+
+	hostedittest.AssertFileEquals(t, path, "testdata/golden/indented.golden")
+}
@@ -0,0 +1,61 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+)
+
+// NewFromGzip parses the gzip-compressed hosts file at path, as large
+// published blocklists are distributed, leniently as New does without
+// isParse. FilePath is empty; use SaveGzip to write the file compressed
+// again, or SaveAs to write it uncompressed.
+func NewFromGzip(path string, opts ...Option) (*HostsEdit, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return NewFromReader(zr, false, opts...)
+}
+
+// SaveGzip writes the content of the hosts file to path, compressed with
+// gzip. The file at path is replaced in one step, as by SaveWithContext.
+// It does not change FilePath, and later saves still write to FilePath.
+func (h *HostsEdit) SaveGzip(path string) error {
+	var b bytes.Buffer
+	zw := gzip.NewWriter(&b)
+	_, err := zw.Write(h.render())
+	if err != nil {
+		return err
+	}
+	err = zw.Close()
+	if err != nil {
+		return err
+	}
+
+	_, err = writeFileAtomic(h.fileSystem(), path, b.Bytes(), h.syncWrites(), func() bool { return true })
+	return err
+}
@@ -0,0 +1,73 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 测试gzip压缩的hosts文件
+func TestGzip(t *testing.T) {
+	hostsContent := "# blocklist\n0.0.0.0 ads.test\n0.0.0.0 tracker.test\n"
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hosts.gz")
+
+	source, _ := NewFromReader(strings.NewReader(hostsContent), false)
+	err := source.SaveGzip(path)
+	if err != nil {
+		t.Fatalf("SaveGzip() error = %v", err)
+	}
+
+	// 文件确实是gzip格式
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	content, _ := io.ReadAll(zr)
+	if string(content) != hostsContent {
+		t.Errorf("decompressed content = %q; want %q", content, hostsContent)
+	}
+
+	hostsEdit, err := NewFromGzip(path)
+	if err != nil {
+		t.Fatalf("NewFromGzip() error = %v", err)
+	}
+	if !hostsEdit.IsBlackholed("tracker.test") || hostsEdit.FilePath != "" {
+		t.Errorf("NewFromGzip() = %v lines, FilePath %q", hostsEdit.Len(), hostsEdit.FilePath)
+	}
+
+	_, err = NewFromGzip(filepath.Join(dir, "missing.gz"))
+	if !os.IsNotExist(err) {
+		t.Errorf("NewFromGzip(missing) error = %v; want not exist", err)
+	}
+	plain := filepath.Join(dir, "hosts")
+	os.WriteFile(plain, []byte(hostsContent), 0o644)
+	_, err = NewFromGzip(plain)
+	if err == nil {
+		t.Errorf("NewFromGzip(uncompressed file) succeeded")
+	}
+}
@@ -0,0 +1,107 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import "strings"
+
+// WithHeader sets a comment block written at the top of the file when it is
+// created or rewritten as a whole, if it does not start with comment lines
+// yet. Each element of lines is the text of one comment line, without the
+// leading "#". Once written, the header is an ordinary part of the file, so
+// later saves keep it without adding it again. With InsertTop, new lines are
+// placed right after the leading comment lines instead of above them.
+//
+// GenericHeader and WindowsHeader return conventional headers.
+func WithHeader(lines []string) Option {
+	return func(h *HostsEdit) {
+		h.header = append([]string(nil), lines...)
+	}
+}
+
+// GenericHeader returns a header for WithHeader that describes the file.
+func GenericHeader() []string {
+	return []string{
+		"This file maps hostnames to IP addresses.",
+		"",
+		"Each entry is an IP address followed by one or more hostnames,",
+		"separated by whitespace. Lines starting with \"#\" are comments.",
+	}
+}
+
+// WindowsHeader returns a header for WithHeader in the style of the hosts
+// file that comes with Windows.
+func WindowsHeader() []string {
+	return []string{
+		"Copyright (c) 1993-2009 Microsoft Corp.",
+		"",
+		"This is a sample HOSTS file used by Microsoft TCP/IP for Windows.",
+		"",
+		"This file contains the mappings of IP addresses to host names. Each",
+		"entry should be kept on an individual line. The IP address should",
+		"be placed in the first column followed by the corresponding host name.",
+		"The IP address and the host name should be separated by at least one",
+		"space.",
+		"",
+		"Additionally, comments (such as these) may be inserted on individual",
+		"lines or following the machine name denoted by a '#' symbol.",
+	}
+}
+
+// hasHeader reports whether the first line that is not blank is a comment
+// that is not a disabled entry.
+func (h *HostsEdit) hasHeader() bool {
+	for _, line := range h.Lines {
+		if !line.isBlank() {
+			return isPlainComment(line)
+		}
+	}
+	return false
+}
+
+// addHeader puts the lines of WithHeader at the top if the file has no
+// header yet.
+func (h *HostsEdit) addHeader() {
+	if h.header == nil || h.hasHeader() {
+		return
+	}
+	lines := make([]*Line, 0, len(h.header)+len(h.Lines))
+	for _, text := range h.header {
+		// 固定为注释行，即使内容看起来像条目
+		lines = append(lines, &Line{IsComment: true, UndefinedRowsRawStr: strings.TrimSpace(text)})
+	}
+	h.Lines = append(lines, h.Lines...)
+}
+
+// topIndex returns the index at which InsertTop places new lines: 0, or
+// the index after the leading comment and blank lines with WithHeader.
+func (h *HostsEdit) topIndex() int {
+	if h.header == nil {
+		return 0
+	}
+	return h.leadingCommentsEnd()
+}
+
+// leadingCommentsEnd returns the index after the leading comment and blank
+// lines.
+func (h *HostsEdit) leadingCommentsEnd() int {
+	for i, line := range h.Lines {
+		if !line.isBlank() && !isPlainComment(line) {
+			return i
+		}
+	}
+	return len(h.Lines)
+}
@@ -0,0 +1,81 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"os"
+	"testing"
+)
+
+// 测试WithHeader选项
+func TestWithHeader(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false, WithHeader([]string{"Managed by myapp", ""}))
+	err = hostsEdit.Edit("a.test", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Edit(a.test, 10.0.0.1) failed with error: %v", err)
+	}
+	// 头部不会重复添加，新条目放在头部之后
+	err = hostsEdit.Edit("b.test", "10.0.0.2")
+	if err != nil {
+		t.Fatalf("Edit(b.test, 10.0.0.2) failed with error: %v", err)
+	}
+
+	want := "# Managed by myapp\n#\n10.0.0.2 b.test\n10.0.0.1 a.test\n127.0.0.1 localhost\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+
+	// 重新加载后已有头部，不再添加
+	hostsEdit, _ = New(filePath, false, WithHeader(WindowsHeader()))
+	err = hostsEdit.Edit("c.test", "10.0.0.3")
+	if err != nil {
+		t.Fatalf("Edit(c.test, 10.0.0.3) failed with error: %v", err)
+	}
+	want = "# Managed by myapp\n#\n10.0.0.3 c.test\n10.0.0.2 b.test\n10.0.0.1 a.test\n127.0.0.1 localhost\n"
+	contentBytes, _ = os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+}
+
+// 测试以禁用条目开头的文件不算有头部
+func TestWithHeaderDisabledEntry(t *testing.T) {
+	filePath, err := createTestHostsFile("# 10.0.0.1 off.test\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false, WithHeader(GenericHeader()[:1]))
+	err = hostsEdit.Save()
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	want := "# This file maps hostnames to IP addresses.\n# 10.0.0.1 off.test\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+}
@@ -0,0 +1,86 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import "time"
+
+// defaultHistorySize is the number of entries GetHistory keeps without
+// WithHistorySize.
+const defaultHistorySize = 100
+
+// HistoryEntry records one change to the mapping of a host, for GetHistory.
+type HistoryEntry struct {
+	Op        EditOpType // OpSet or OpRemove
+	Host      string
+	IP        string // "" for OpRemove
+	PrevIP    string // "" if the host had no active entry
+	Timestamp time.Time
+}
+
+// WithHistorySize sets the number of entries GetHistory keeps, 100 by
+// default. With n of 0 or less, no history is kept.
+func WithHistorySize(n int) Option {
+	return func(h *HostsEdit) {
+		if n <= 0 {
+			n = -1
+		}
+		h.historySize = n
+	}
+}
+
+// GetHistory returns the latest changes made to the mapping of hosts,
+// newest first. Edit, Delete, EditBatch, EditInSection, DeleteFunc and
+// ReplaceIP, and the methods built on them such as EnsureExists, add an
+// entry for every host they change, whether or not the save that follows
+// succeeds. The methods of Profile add one only if the IP address the host
+// resolves to changes, which is not the case in a disabled profile.
+// Methods that replace many lines at once, such as Transform, Import or
+// changing Lines directly, are not recorded. Timestamps come from WithClock.
+func (h *HostsEdit) GetHistory() []HistoryEntry {
+	entries := make([]HistoryEntry, len(h.history))
+	for i, e := range h.history {
+		entries[len(entries)-1-i] = e
+	}
+	return entries
+}
+
+// recordMappingChange adds an entry for host if its IP address is no longer
+// prevIP.
+func (h *HostsEdit) recordMappingChange(host, prevIP string) {
+	ip, ok := h.Get(host)
+	switch {
+	case !ok && prevIP != "":
+		h.recordHistory(OpRemove, host, "", prevIP)
+	case ok && ip != prevIP:
+		h.recordHistory(OpSet, host, ip, prevIP)
+	}
+}
+
+// recordHistory adds an entry for a change of host to the history.
+func (h *HostsEdit) recordHistory(op EditOpType, host, ip, prevIP string) {
+	size := h.historySize
+	if size == 0 {
+		size = defaultHistorySize
+	}
+	if size < 0 {
+		return
+	}
+	h.history = append(h.history, HistoryEntry{Op: op, Host: host, IP: ip, PrevIP: prevIP, Timestamp: h.clock()})
+	if len(h.history) > size {
+		h.history = append(h.history[:0:0], h.history[len(h.history)-size:]...)
+	}
+}
@@ -0,0 +1,69 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// 测试GetHistory方法
+func TestGetHistory(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n10.0.0.1 a.test b.test\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	hostsEdit, _ := New(filePath, false, WithClock(func() time.Time { return now }))
+	hostsEdit.Edit("a.test", "10.0.0.2")
+	hostsEdit.Edit("a.test", "10.0.0.2")
+	hostsEdit.Delete("missing.test")
+	hostsEdit.Delete("b.test")
+	hostsEdit.ReplaceIP("127.0.0.1", "127.0.0.2")
+	hostsEdit.EditBatch([]EditOp{{Op: OpRename, Host: "a.test", NewHost: "c.test"}})
+
+	want := []HistoryEntry{
+		{OpSet, "c.test", "10.0.0.2", "", now},
+		{OpRemove, "a.test", "", "10.0.0.2", now},
+		{OpSet, "localhost", "127.0.0.2", "127.0.0.1", now},
+		{OpRemove, "b.test", "", "10.0.0.1", now},
+		{OpSet, "a.test", "10.0.0.2", "10.0.0.1", now},
+	}
+	if got := hostsEdit.GetHistory(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetHistory() = %v; want %v", got, want)
+	}
+
+	// 历史的长度有上限
+	hostsEdit, _ = New(filePath, false, WithHistorySize(2))
+	hostsEdit.Edit("x.test", "10.0.1.1")
+	hostsEdit.Edit("y.test", "10.0.1.2")
+	hostsEdit.Edit("z.test", "10.0.1.3")
+	got := hostsEdit.GetHistory()
+	if len(got) != 2 || got[0].Host != "z.test" || got[1].Host != "y.test" {
+		t.Errorf("GetHistory() with WithHistorySize(2) = %v; want z.test and y.test", got)
+	}
+
+	hostsEdit, _ = New(filePath, false, WithHistorySize(0))
+	hostsEdit.Edit("x.test", "10.0.1.4")
+	if got := hostsEdit.GetHistory(); len(got) != 0 {
+		t.Errorf("GetHistory() with WithHistorySize(0) = %v; want none", got)
+	}
+}
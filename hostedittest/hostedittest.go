@@ -0,0 +1,143 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package hostedittest provides helpers for testing code that edits hosts
+// files with the hostedit package.
+package hostedittest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	hostedit "github.com/Deng-Xian-Sheng/go-hosts-edit-library"
+)
+
+// WriteTempHosts writes content to a new file in a temporary directory that
+// is removed when the test ends, and returns the path of the file.
+func WriteTempHosts(t testing.TB, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write temp hosts file: %v", err)
+	}
+	return path
+}
+
+// AssertFileEquals fails the test if the file at path does not have the
+// same content as the golden file, and prints a line by line diff.
+func AssertFileEquals(t testing.TB, path, golden string) {
+	t.Helper()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("read golden file %s: %v", golden, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from %s (-want +got):\n%s", path, golden, Diff(string(want), string(got)))
+	}
+}
+
+// AssertContent fails the test if the file at path does not hold want, and
+// prints a line by line diff.
+func AssertContent(t testing.TB, path, want string) {
+	t.Helper()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	if string(got) != want {
+		t.Errorf("%s differs from the expected content (-want +got):\n%s", path, Diff(want, string(got)))
+	}
+}
+
+// AssertRoundTrip fails the test if loading content with
+// hostedit.WithPreserveFormatting and saving it again does not produce
+// the same bytes, and prints a line by line diff.
+func AssertRoundTrip(t testing.TB, content string) {
+	t.Helper()
+	path := WriteTempHosts(t, content)
+	h, err := hostedit.New(path, false, hostedit.WithPreserveFormatting())
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if err := h.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	if string(got) != content {
+		t.Errorf("round trip changed the content (-want +got):\n%s", Diff(content, string(got)))
+	}
+}
+
+// Diff returns a line by line diff of want and got, with "-" in front of
+// lines only in want, "+" in front of lines only in got and two spaces in
+// front of common lines. Line endings are shown quoted, so a changed line
+// ending is visible.
+func Diff(want, got string) string {
+	a, b := splitLines(want), splitLines(got)
+
+	// lcs[i][j] 是a[i:]和b[j:]的最长公共子序列长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var sb strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			fmt.Fprintf(&sb, "  %q\n", a[i])
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			fmt.Fprintf(&sb, "+ %q\n", b[j])
+			j++
+		default:
+			fmt.Fprintf(&sb, "- %q\n", a[i])
+			i++
+		}
+	}
+	return sb.String()
+}
+
+// splitLines splits s into lines that keep their line endings.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,40 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedittest
+
+import "testing"
+
+// 测试Diff函数
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		want string
+		got  string
+		diff string
+	}{
+		{"a\nb\n", "a\nb\n", "  \"a\\n\"\n  \"b\\n\"\n"},
+		{"a\nb\nc\n", "a\nc\n", "  \"a\\n\"\n- \"b\\n\"\n  \"c\\n\"\n"},
+		{"a\n", "a\nb\n", "  \"a\\n\"\n+ \"b\\n\"\n"},
+		{"a\r\n", "a\n", "+ \"a\\n\"\n- \"a\\r\\n\"\n"},
+		{"a\n", "a", "+ \"a\"\n- \"a\\n\"\n"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		if got := Diff(tt.want, tt.got); got != tt.diff {
+			t.Errorf("Diff(%q, %q) = %q; want %q", tt.want, tt.got, got, tt.diff)
+		}
+	}
+}
@@ -18,11 +18,20 @@ package hostedit
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"math"
 	"net"
+	"net/netip"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 /*
@@ -31,75 +40,321 @@ x.x.x.x xxx xxx xxx
 x.x.x.x xxx
 */
 
-type Line struct {
-	IsComment           bool
-	UndefinedRowsRawStr string
-	IP                  string
-	Host                map[string]struct{} // 注意，会使多个主机之间无序，但是这貌似是不可避免的。
-	IsDelete            bool
-}
-
 // HostsEdit represents the entire hosts file and provides methods to manipulate it.
 type HostsEdit struct {
+	// Lines holds the lines of the file in order.
+	//
+	// Deprecated: Changing a Line through this field bypasses the tracking
+	// of modified lines and the host index. Use Len and LineAt to read the
+	// lines, and the methods of HostsEdit to change them. The field will be
+	// unexported in the next major version.
 	Lines    []*Line
 	FilePath string
+
+	isParse         bool
+	preserve        bool
+	bom             bool // write a UTF-8 byte order mark before the content
+	encodingSet     bool
+	eol             string
+	insertPolicy    InsertPolicy
+	reuseDisabled   bool
+	indent          string
+	header          []string
+	maxHostsPerLine int
+	createMode      os.FileMode
+	store           Store // nil for the file at FilePath
+	autoNormalize   bool
+	readOnly        bool
+	strictHostnames bool
+	fsync           bool // sync writes to disk, if fsyncSet
+	fsyncSet        bool
+	lowercaseHosts  bool
+	annotateTool    string           // tool named in change annotations, "" for none
+	now             func() time.Time // nil for time.Now
+	debounce        *debouncer
+	onChange        []func(op ChangeOp, host, ip string)
+	subMu           sync.Mutex // guards subscribers, watchers and notified
+	subscribers     []chan<- HostEvent
+	watchers        []*watcher
+	notified        map[string]string // effective mapping last reported to onChange
+	notifiedOrder   []string
+	fs              fileSystem // nil for the operating system
+	writeAttempts   int        // 0 for the default of WithWriteRetry
+	writeDelay      time.Duration
+	lastWrite       WriteStrategy
+	logger          *slog.Logger
+	wsl             bool // the Windows hosts file edited from WSL
+	httpTimeout     time.Duration
+	machineHostname bool // EnsureLocalhostDefaults also maps os.Hostname
+	renderConfig    renderConfig
+	beforeSave      []func(lines []*Line) error
+	auditLog        *auditLog
+	undoDepth       int // 0 for no undo history
+	undo, redo      []*undoStep
+	historySize     int // 0 for the default, negative for none
+	history         []HistoryEntry
+
+	// synced is the list of lines as it was last loaded or saved, and
+	// syncedSize the size of the file at that time. They let saveToFile
+	// detect when only new lines were appended.
+	synced     []*Line
+	syncedSize int64
 }
 
+// InsertPolicy decides where Edit places the line for a new host.
+type InsertPolicy int
+
+const (
+	// InsertTop places new lines at the top of the file, so they take
+	// precedence over any later line for the same host. This is the default.
+	InsertTop InsertPolicy = iota
+	// InsertAppend places new lines at the end of the file. When the only
+	// change since the last save is appended lines, they are appended to the
+	// file instead of rewriting it, and existing lines keep their formatting.
+	// Changes made by assigning the fields of an existing Line directly are
+	// not detected, so they must not be mixed with this policy.
+	InsertAppend
+	// InsertGroupByDomain places a new line right after the last entry line
+	// with a host that shares the longest domain suffix with the new host,
+	// so that foo.example.com is placed next to example.com. Suffixes are
+	// compared on label boundaries and must have at least two labels, so
+	// notexample.com and other.com do not match example.com. Without a
+	// match, new lines are placed at the top as with InsertTop.
+	InsertGroupByDomain
+)
+
 // New loads the hosts file from the specified path and returns a HostsEdit instance.
 // isParse 是否进行严格的语法分析，如果启用则不容忍注释行以外的重复的主机、不规范的主机的条目，遇到此类会报错。但操作系统在这种情况下往往不会报错，与操作系统的行为不符。
-func New(filePath string, isParse bool) (*HostsEdit, error) {
+func New(filePath string, isParse bool, opts ...Option) (*HostsEdit, error) {
+	h := &HostsEdit{FilePath: filePath, isParse: isParse, eol: "\n"}
+	for _, opt := range opts {
+		opt(h)
+	}
+
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
 
-	var lines []*Line
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		lineText := scanner.Text()
-		line := Line{
-			Host: make(map[string]struct{}),
-		}
+	changed, err := h.load(file)
+	if err != nil {
+		return nil, err
+	}
 
-		lineText = strings.TrimSpace(lineText)
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		h.markSynced(info.Size())
+	}
 
-		if lineText == "" {
-			continue
-		}
+	return h, nil
+}
 
-		if strings.HasPrefix(lineText, "#") {
-			line.IsComment = true
-			lineText = strings.TrimSpace(strings.TrimPrefix(lineText, "#"))
+// NewOrCreate is like New without strict parsing, but creates the hosts file
+// if it does not exist,
+// empty or with only the header of WithHeader, and with the permissions of
+// WithCreateMode. The parent directory must exist. If another process
+// creates the file at the same time, the file it created is loaded.
+func NewOrCreate(filePath string, opts ...Option) (*HostsEdit, error) {
+	// 与并发的创建者竞争时重试，对方创建后又删除的情况最多重试几次
+	for attempt := 0; ; attempt++ {
+		h, err := New(filePath, false, opts...)
+		if !errors.Is(err, os.ErrNotExist) || attempt == 3 {
+			return h, err
 		}
-
-		entries := strings.Fields(lineText)
-		if len(entries) >= 2 && net.ParseIP(entries[0]) != nil {
-			line.IP = entries[0]
-			for _, v := range entries[1:] {
-				line.Host[v] = struct{}{}
-			}
-		} else {
-			line.UndefinedRowsRawStr = lineText
+		err = createHostsFile(filePath, opts)
+		if err != nil && !errors.Is(err, os.ErrExist) {
+			return nil, err
 		}
+	}
+}
 
-		lines = append(lines, &line)
+// createHostsFile creates the file at filePath for NewOrCreate, failing
+// with os.ErrExist if it already exists.
+func createHostsFile(filePath string, opts []Option) error {
+	h := &HostsEdit{FilePath: filePath, eol: "\n", createMode: 0o644}
+	for _, opt := range opts {
+		opt(h)
 	}
+	h.addHeader()
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, h.createMode)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("cannot create hosts file, directory %s does not exist: %w", filepath.Dir(filePath), err)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(h.render())
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// load replaces the lines of h with the hosts file content read from r and
+// reports whether the lines were changed after parsing, by WithAutoNormalize.
+func (h *HostsEdit) load(r io.Reader) (changed bool, err error) {
+	r, bom := stripBOM(r)
+	if !h.encodingSet {
+		// 保留格式时也保留原文件的BOM
+		h.bom = bom && h.preserve
+	}
+
+	lines, err := parseLines(r, h.preserve)
+	if err != nil {
+		return false, err
+	}
+
+	if h.autoNormalize {
+		// 先规范化再检查，避免大小写不同的重复主机漏检
+		changed = normalizeIPs(lines)
+		changed = normalizeHosts(lines) || changed
 	}
 
-	if isParse {
+	if h.isParse {
 		err = parse(lines)
 		if err != nil {
-			return nil, err
+			return false, err
+		}
+	}
+	if h.strictHostnames {
+		err = validateHostnames(lines)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	h.Lines = lines
+	if h.preserve && len(lines) > 0 && lines[0].eol == "\r\n" {
+		h.eol = "\r\n"
+	}
+	h.resetAudit()
+	h.clearHistory()
+	return changed, nil
+}
+
+// parseLines reads hosts file content from r and splits it into lines.
+// If preserve is set, blank lines are kept and every line remembers its
+// original text so it can be written back unchanged.
+func parseLines(r io.Reader, preserve bool) ([]*Line, error) {
+	var lines []*Line
+	err := scanEntries(r, preserve, func(line *Line) error {
+		lines = append(lines, line)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// scanEntries parses hosts file content from r line by line and calls fn
+// for every line parseLines would return, stopping at the first error.
+func scanEntries(r io.Reader, preserve bool, fn func(line *Line) error) error {
+	s := newLineScanner(r, preserve)
+	for {
+		line, ok := s.next()
+		if !ok {
+			return s.err()
+		}
+		if err := fn(line); err != nil {
+			return err
 		}
 	}
+}
+
+// lineScanner parses hosts file content one line at a time.
+type lineScanner struct {
+	scanner  *bufio.Scanner
+	preserve bool
+	num      int
+}
+
+func newLineScanner(r io.Reader, preserve bool) *lineScanner {
+	scanner := bufio.NewScanner(r)
+	// 不限制行的长度，合并后的屏蔽列表中可能有很长的行
+	scanner.Buffer(nil, math.MaxInt)
+	scanner.Split(scanLines)
+	return &lineScanner{scanner: scanner, preserve: preserve}
+}
+
+// next returns the next line parseLines would return, or false at the end
+// of the content or on a read error.
+func (s *lineScanner) next() (*Line, bool) {
+	for s.scanner.Scan() {
+		s.num++
+		text, eol := splitEOL(s.scanner.Text())
+		line, _ := ParseLine(text)
+		line.num = s.num
+
+		if s.preserve {
+			line.raw, line.eol = text, eol
+			line.verbatim = true
+			if !line.isBlank() {
+				line.indent = text[:len(text)-len(strings.TrimLeft(text, " \t"))]
+			}
+		} else if line.isBlank() {
+			continue
+		}
+		return line, true
+	}
+	return nil, false
+}
+
+// err returns the read error that stopped next, if any.
+func (s *lineScanner) err() error {
+	return s.scanner.Err()
+}
+
+// utf8BOM is the UTF-8 byte order mark some Windows editors put at the start
+// of text files.
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+// stripBOM returns a reader for the content of r without a leading UTF-8
+// byte order mark, and reports whether there was one.
+func stripBOM(r io.Reader) (io.Reader, bool) {
+	br := bufio.NewReader(r)
+	prefix, _ := br.Peek(len(utf8BOM))
+	if bytes.Equal(prefix, utf8BOM) {
+		br.Discard(len(utf8BOM))
+		return br, true
+	}
+	return br, false
+}
+
+// scanLines is a bufio.SplitFunc like bufio.ScanLines, except that the line
+// ending is kept in the token.
+func scanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i+1], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
 
-	return &HostsEdit{Lines: lines, FilePath: filePath}, nil
+// splitEOL separates a token returned by scanLines into text and line ending.
+func splitEOL(s string) (text, eol string) {
+	switch {
+	case strings.HasSuffix(s, "\r\n"):
+		return s[:len(s)-2], "\r\n"
+	case strings.HasSuffix(s, "\n"):
+		return s[:len(s)-1], "\n"
+	}
+	return s, ""
 }
 
+// parse checks lines for the strict mode of New. It returns a *ParseError
+// for the first entry that is malformed or repeats a host.
 func parse(lines []*Line) (err error) {
 	allHost := make(map[string]struct{})
 	for _, line := range lines {
@@ -107,26 +362,73 @@ func parse(lines []*Line) (err error) {
 			continue
 		}
 		if line.UndefinedRowsRawStr != "" {
-			return errors.New("not comment but UndefinedRowsRawStr")
+			err := &ParseError{Line: line.num, Text: line.UndefinedRowsRawStr, Err: ErrMalformedLine}
+			err.Column, err.Length = line.malformedSpan()
+			return err
 		}
-		for k := range line.Host {
-			if _, ok := allHost[k]; !ok {
-				allHost[k] = struct{}{}
+		if len(line.Extra) > 0 {
+			err := &ParseError{Line: line.num, Text: line.Extra[0], Err: ErrMalformedLine}
+			err.Column, err.Length = line.fieldSpan(len(line.hosts) + 1)
+			return err
+		}
+		for i, k := range line.hosts {
+			if _, ok := allHost[lowerASCII(k)]; !ok {
+				allHost[lowerASCII(k)] = struct{}{}
 			} else {
-				return errors.New("host repeat")
+				err := &ParseError{Line: line.num, Text: k, Err: ErrDuplicateHost}
+				err.Column, err.Length = line.fieldSpan(i + 1)
+				return err
 			}
 		}
 	}
 	return nil
 }
 
+// Len returns the number of lines, the bound of the indexes accepted by
+// LineAt and DeleteLine.
+func (h *HostsEdit) Len() int {
+	return len(h.Lines)
+}
+
+// LineAt returns a copy of the line at index i. Changing the copy, or the
+// result of its Hosts method, does not change the hosts file. If i is out
+// of range, an error wrapping ErrIndexOutOfRange is returned.
+func (h *HostsEdit) LineAt(i int) (Line, error) {
+	if err := h.checkIndex(i); err != nil {
+		return Line{}, err
+	}
+	return *h.Lines[i].clone(), nil
+}
+
+// GetLineIndex returns the index of the active entry line for host, the
+// first one if there are several, for use with LineAt, DeleteLine and the
+// other methods that take a line index. It returns -1 if host has no
+// active entry.
+func (h *HostsEdit) GetLineIndex(host string) int {
+	for i, line := range h.Lines {
+		if isActive(line) && line.HasHost(host) {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkIndex returns an error wrapping ErrIndexOutOfRange if i is not an
+// index of Lines.
+func (h *HostsEdit) checkIndex(i int) error {
+	if i < 0 || i >= len(h.Lines) {
+		return fmt.Errorf("%w: %d of %d lines", ErrIndexOutOfRange, i, len(h.Lines))
+	}
+	return nil
+}
+
 // Get returns the IP address of the specified host.
 func (h *HostsEdit) Get(host string) (string, bool) {
 	for _, line := range h.Lines {
 		if line.IsComment || line.UndefinedRowsRawStr != "" {
 			continue
 		}
-		if _, exists := line.Host[host]; exists {
+		if line.HasHost(host) {
 			return line.IP, true
 		}
 	}
@@ -139,58 +441,232 @@ func (h *HostsEdit) Exists(host string) bool {
 	return exists
 }
 
-// Edit adds or updates the specified host with the given IP address.
-func (h *HostsEdit) Edit(host, ip string) (err error) {
-	if strings.TrimSpace(host) == "" || strings.TrimSpace(ip) == "" {
-		return errors.New("host or ip cannot be empty")
+// Contains reports whether an active entry maps host to ip. Unlike Get, it
+// also finds a mapping on a line after the first one for host, such as
+// "::1 localhost" after "127.0.0.1 localhost".
+func (h *HostsEdit) Contains(host, ip string) bool {
+	addr, _ := netip.ParseAddr(ip)
+	for _, line := range h.Lines {
+		if isActive(line) && line.hasAddr(ip, addr) && line.HasHost(host) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsLocalhostMapped reports whether the effective IP address of host, the
+// one Get returns, is a loopback address: 127.0.0.0/8 or ::1. It is false
+// for a host without an active entry. 0.0.0.0 and :: are not loopback
+// addresses; IsBlackholed reports those.
+func (h *HostsEdit) IsLocalhostMapped(host string) bool {
+	ip, ok := h.Get(host)
+	return ok && net.ParseIP(ip).IsLoopback()
+}
+
+// IsBlackholed reports whether the effective IP address of host is the
+// unspecified address 0.0.0.0 or ::, which blocking lists use to make a
+// host unreachable. It is false for a host without an active entry.
+func (h *HostsEdit) IsBlackholed(host string) bool {
+	ip, ok := h.Get(host)
+	return ok && net.ParseIP(ip).IsUnspecified()
+}
+
+// Family is an IP address family.
+type Family int
+
+const (
+	// IPv4 is the family of IPv4 addresses, including IPv4-mapped IPv6
+	// addresses such as ::ffff:127.0.0.1.
+	IPv4 Family = iota
+	// IPv6 is the family of all other IPv6 addresses.
+	IPv6
+)
+
+// GetFamily returns the IP address of the first line that maps the specified
+// host to an address of the given family, like Get restricted to that family.
+func (h *HostsEdit) GetFamily(host string, family Family) (string, bool) {
+	for _, line := range h.Lines {
+		if line.IsComment || line.UndefinedRowsRawStr != "" || !line.HasHost(host) {
+			continue
+		}
+		ip := net.ParseIP(line.IP)
+		if ip == nil {
+			continue
+		}
+		if (ip.To4() != nil) == (family == IPv4) {
+			return line.IP, true
+		}
 	}
+	return "", false
+}
 
+// GetLineForHost returns the line that maps the specified host, or nil if
+// the host does not exist.
+func (h *HostsEdit) GetLineForHost(host string) *Line {
 	for _, line := range h.Lines {
 		if line.IsComment || line.UndefinedRowsRawStr != "" {
 			continue
 		}
-		if _, exists := line.Host[host]; exists {
-			if line.IP == ip {
-				return
-			}
-			if len(line.Host) > 1 {
-				delete(line.Host, host)
-			} else {
-				line.IP = ip
-				err = saveToFile(h.Lines, h.FilePath)
-				if err != nil {
-					return err
-				}
-				return
-			}
+		if line.HasHost(host) {
+			return line
 		}
 	}
+	return nil
+}
 
+// GetInlineComment returns the inline comment of the line that maps the
+// specified host, or "" if the host does not exist or the line has none.
+func (h *HostsEdit) GetInlineComment(host string) string {
+	if line := h.GetLineForHost(host); line != nil {
+		return line.InlineComment
+	}
+	return ""
+}
+
+// SetInlineComment sets the inline comment of the line that maps the
+// specified host and saves the file, or removes the comment if comment is
+// empty. The comment applies to the whole line, so it is shared with the
+// other hosts of the line. If the host does not exist, ErrHostNotFound is
+// returned. The file is not written if the comment is unchanged.
+func (h *HostsEdit) SetInlineComment(host, comment string) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	if strings.ContainsAny(comment, "\r\n") {
+		return errors.New("comment cannot contain line breaks")
+	}
+	line := h.GetLineForHost(host)
+	if line == nil {
+		return ErrHostNotFound
+	}
+	comment = strings.TrimSpace(comment)
+	if line.InlineComment == comment {
+		return nil
+	}
+	line.InlineComment = comment
+	line.touch()
+	return h.saveToFile(operation{name: "SetInlineComment", host: host})
+}
+
+// GetLineForIP returns the first line that uses the specified IP address,
+// or nil if no line uses it.
+func (h *HostsEdit) GetLineForIP(ip string) *Line {
+	addr, _ := netip.ParseAddr(ip)
 	for _, line := range h.Lines {
 		if line.IsComment || line.UndefinedRowsRawStr != "" {
 			continue
 		}
-		if line.IP == ip {
-			line.Host[host] = struct{}{}
-			err = saveToFile(h.Lines, h.FilePath)
-			if err != nil {
-				return err
+		if line.IP != "" && line.hasAddr(ip, addr) {
+			return line
+		}
+	}
+	return nil
+}
+
+// GetAllLinesForIP returns every line that uses the specified IP address.
+// The result is empty, not nil, if no line uses it.
+func (h *HostsEdit) GetAllLinesForIP(ip string) []*Line {
+	addr, _ := netip.ParseAddr(ip)
+	lines := []*Line{}
+	for _, line := range h.Lines {
+		if line.IsComment || line.UndefinedRowsRawStr != "" {
+			continue
+		}
+		if line.IP != "" && line.hasAddr(ip, addr) {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// GetActiveLines returns copies of the entry lines, the lines the operating
+// system uses, in file order. Changing them does not change the hosts file.
+func (h *HostsEdit) GetActiveLines() []*Line {
+	lines := []*Line{}
+	for _, line := range h.Lines {
+		if isActive(line) {
+			lines = append(lines, line.clone())
+		}
+	}
+	return lines
+}
+
+// GetInactiveLines returns copies of all the other lines: comments,
+// including disabled entries, malformed lines and blank lines.
+func (h *HostsEdit) GetInactiveLines() []*Line {
+	lines := []*Line{}
+	for _, line := range h.Lines {
+		if !isActive(line) {
+			lines = append(lines, line.clone())
+		}
+	}
+	return lines
+}
+
+// isActive reports whether the line is an entry that is not commented out.
+func isActive(line *Line) bool {
+	return !line.IsComment && line.UndefinedRowsRawStr == "" && line.IP != ""
+}
+
+// GroupByIP returns every IP address in use mapped to the sorted hosts of
+// all lines with that address.
+func (h *HostsEdit) GroupByIP() map[string][]string {
+	groups := make(map[string][]string)
+	for _, line := range h.Lines {
+		if line.IsComment || line.UndefinedRowsRawStr != "" || line.IP == "" {
+			continue
+		}
+		for _, host := range line.hosts {
+			if !contains(groups[line.IP], host) {
+				groups[line.IP] = append(groups[line.IP], host)
 			}
-			return
 		}
 	}
+	for _, hosts := range groups {
+		sort.Strings(hosts)
+	}
+	return groups
+}
 
-	// 头部追加，防止因主机重导致操作系统识别的时候忽视
-	h.Lines = append([]*Line{{
-		IsComment:           false,
-		UndefinedRowsRawStr: "",
-		IP:                  ip,
-		Host: map[string]struct{}{
-			host: {},
-		},
-	}}, h.Lines...)
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Edit adds or updates the specified host with the given IP address.
+// With WithLowercaseHosts, host is written in lowercase.
+// If the host appears on several lines, it is removed from all but the first
+// one, so the updated entry is the one the operating system uses.
+//
+// IP addresses are compared by value, here and in every method that looks
+// for an address, so ::1 and 0:0:0:0:0:0:0:1 are the same address. A line
+// that already maps host to an equal address is kept as written.
+//
+// The file is replaced atomically as described on Save.
+func (h *HostsEdit) Edit(host, ip string) (err error) {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	if strings.TrimSpace(host) == "" || strings.TrimSpace(ip) == "" {
+		return errors.New("host or ip cannot be empty")
+	}
 
-	err = saveToFile(h.Lines, h.FilePath)
+	if h.lowercaseHosts {
+		host = lowerASCII(host)
+	}
+	step := h.beginStep(hostOrAddr([]string{host}, []string{ip}))
+	prevIP, _ := h.Get(host)
+	if !h.set(host, ip) {
+		return
+	}
+	h.recordHistory(OpSet, host, ip, prevIP)
+
+	err = h.saveToFile(operation{name: "Edit", host: host, ip: ip})
+	h.endStep(step)
 	if err != nil {
 		return err
 	}
@@ -198,37 +674,145 @@ func (h *HostsEdit) Edit(host, ip string) (err error) {
 	return
 }
 
-// Delete removes the specified host from the hosts file.
-// not exists no error
-func (h *HostsEdit) Delete(host string) (err error) {
+// set maps host to ip in memory and reports whether anything changed.
+func (h *HostsEdit) set(host, ip string) bool {
+	var first *Line
+	changed := false
+	for _, line := range h.Lines {
+		if line.IsComment || line.UndefinedRowsRawStr != "" || !line.HasHost(host) {
+			continue
+		}
+		if first == nil {
+			first = line
+			continue
+		}
+		// 清理重复出现的主机，避免旧的条目残留
+		line.RemoveHost(host)
+		if len(line.hosts) == 0 {
+			line.IsDelete = true
+		}
+		changed = true
+	}
+	if changed {
+		h.dropDeleted()
+	}
+
+	if first != nil {
+		if sameIP(first.IP, ip) {
+			return changed
+		}
+		if first.onlyHost(host) {
+			first.setIP(ip)
+			first.touch()
+			return true
+		}
+		first.RemoveHost(host)
+	} else if h.reuseDisabled && h.enableDisabled(host, ip) {
+		return true
+	}
+
+	h.insertHost(host, ip)
+	return true
+}
+
+// enableDisabled re-enables the first disabled entry for host with the given
+// ip and reports whether one was found. If the disabled line also lists
+// other hosts, only host is taken out of it, into a new line just above it.
+func (h *HostsEdit) enableDisabled(host, ip string) bool {
+	for i, line := range h.Lines {
+		if !line.IsDisabled() || !line.HasHost(host) {
+			continue
+		}
+		if line.onlyHost(host) {
+			line.IsComment = false
+			line.setIP(ip)
+			line.touch()
+			return true
+		}
+		line.RemoveHost(host)
+		newLine := NewLine(ip, host)
+		newLine.indent = line.indent
+		h.Lines = append(h.Lines[:i], append([]*Line{newLine}, h.Lines[i:]...)...)
+		return true
+	}
+	return false
+}
+
+// insertHost adds host to the first entry line that uses ip, or to a new
+// line placed according to the insert policy.
+func (h *HostsEdit) insertHost(host, ip string) {
+	// 按解析后的地址比较，写法不同的相同地址也合并到同一行
+	addr, _ := netip.ParseAddr(ip)
 	for _, line := range h.Lines {
 		if line.IsComment || line.UndefinedRowsRawStr != "" {
 			continue
 		}
-		if _, exists := line.Host[host]; exists {
-			if len(line.Host) > 1 {
-				delete(line.Host, host)
-				err = saveToFile(h.Lines, h.FilePath)
-				if err != nil {
-					return
-				}
-				return
-			} else {
-				line.IsDelete = true
+		if line.hasAddr(ip, addr) {
+			line.AddHost(host)
+			return
+		}
+	}
+
+	newLine := NewLine(ip, host)
+	newLine.indent = h.indent
+	if h.insertPolicy == InsertAppend {
+		h.Lines = append(h.Lines, newLine)
+	} else if i := h.domainGroupEnd(host); h.insertPolicy == InsertGroupByDomain && i >= 0 {
+		h.Lines = append(h.Lines[:i+1], append([]*Line{newLine}, h.Lines[i+1:]...)...)
+	} else {
+		// 头部追加，防止因主机重导致操作系统识别的时候忽视
+		i := h.topIndex()
+		h.Lines = append(h.Lines[:i], append([]*Line{newLine}, h.Lines[i:]...)...)
+	}
+}
+
+// domainGroupEnd returns the index of the last entry line with a host that
+// shares the longest domain suffix of at least two labels with host, or -1.
+func (h *HostsEdit) domainGroupEnd(host string) int {
+	index, longest := -1, 2
+	for i, line := range h.Lines {
+		if line.IsComment || line.UndefinedRowsRawStr != "" {
+			continue
+		}
+		for _, v := range line.hosts {
+			if n := commonSuffixLabels(host, v); n >= longest {
+				index, longest = i, n
 			}
 		}
 	}
+	return index
+}
 
-	var updatedLines []*Line
-	for _, line := range h.Lines {
-		if !line.IsDelete {
-			updatedLines = append(updatedLines, line)
+// commonSuffixLabels returns the number of trailing labels a and b share.
+func commonSuffixLabels(a, b string) int {
+	labelsA := strings.Split(strings.TrimSuffix(a, "."), ".")
+	labelsB := strings.Split(strings.TrimSuffix(b, "."), ".")
+	n := 0
+	for n < len(labelsA) && n < len(labelsB) {
+		if !strings.EqualFold(labelsA[len(labelsA)-1-n], labelsB[len(labelsB)-1-n]) {
+			break
 		}
+		n++
 	}
+	return n
+}
 
-	h.Lines = updatedLines
+// Delete removes the specified host from the hosts file.
+// not exists no error, and the file is not written in that case.
+// The file is replaced atomically as described on Save.
+func (h *HostsEdit) Delete(host string) (err error) {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	step := h.beginStep(hostOrAddr([]string{host}, nil))
+	prevIP, _ := h.Get(host)
+	if !h.remove(host) {
+		return nil
+	}
+	h.recordHistory(OpRemove, host, "", prevIP)
 
-	err = saveToFile(h.Lines, h.FilePath)
+	err = h.saveToFile(operation{name: "Delete", host: host})
+	h.endStep(step)
 	if err != nil {
 		return
 	}
@@ -236,51 +820,157 @@ func (h *HostsEdit) Delete(host string) (err error) {
 	return
 }
 
-// saveToFile writes the current hosts file configuration back to disk.
-func saveToFile(lines []*Line, filePath string) error {
-	file, err := os.Create(filePath)
-	if err != nil {
-		return err
+// DeleteFunc calls fn for every host of every active entry, with the IP
+// address of the entry, and removes the hosts for which fn returns true,
+// dropping the lines left without hosts. It returns the number of hosts
+// removed and saves the file once, only if a host was removed. All calls to
+// fn are made before anything is removed, so fn sees the lines as they were
+// when DeleteFunc was called.
+func (h *HostsEdit) DeleteFunc(fn func(ip, host string) bool) (removed int, err error) {
+	if h.readOnly {
+		return 0, ErrReadOnly
 	}
-	defer file.Close()
 
-	for _, line := range lines {
-		if line.IsComment {
-			_, err := fmt.Fprint(file, "# ")
-			if err != nil {
-				return err
-			}
+	type pair struct {
+		line *Line
+		host string
+	}
+	var selected []pair
+	for _, line := range h.Lines {
+		if !isActive(line) {
+			continue
 		}
-		if line.UndefinedRowsRawStr != "" {
-			_, err := fmt.Fprint(file, line.UndefinedRowsRawStr)
-			if err != nil {
-				return err
+		for _, host := range line.hosts {
+			if fn(line.IP, host) {
+				selected = append(selected, pair{line, host})
 			}
-		} else {
-			_, err := fmt.Fprint(file, line.IP, " ")
-			if err != nil {
-				return err
+		}
+	}
+
+	for _, p := range selected {
+		if p.line.RemoveHost(p.host) {
+			removed++
+			h.recordHistory(OpRemove, p.host, "", p.line.IP)
+			if len(p.line.hosts) == 0 {
+				p.line.IsDelete = true
 			}
+		}
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	h.dropDeleted()
+
+	err = h.saveToFile(operation{name: "DeleteFunc"})
+	if err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+// DeleteLine removes the line at index i of Lines, whatever it holds, and
+// saves the file. Unlike Delete, it can remove comments and malformed lines.
+// The lines after i move down by one, so to remove several lines in one go,
+// delete them from the highest index to the lowest. If i is out of range,
+// an error wrapping ErrIndexOutOfRange is returned and nothing is changed.
+func (h *HostsEdit) DeleteLine(i int) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	if err := h.checkIndex(i); err != nil {
+		return err
+	}
+	h.Lines = append(h.Lines[:i:i], h.Lines[i+1:]...)
+	return h.saveToFile(operation{name: "DeleteLine"})
+}
+
+// SwapLines exchanges the lines at indexes i and j, whatever they hold, and
+// saves the file, for example to move an entry above another one so that
+// it takes precedence. If either index is out of range, an error wrapping
+// ErrIndexOutOfRange is returned and nothing is changed.
+func (h *HostsEdit) SwapLines(i, j int) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	if err := h.checkIndex(i); err != nil {
+		return err
+	}
+	if err := h.checkIndex(j); err != nil {
+		return err
+	}
+	h.Lines[i], h.Lines[j] = h.Lines[j], h.Lines[i]
+	return h.saveToFile(operation{name: "SwapLines"})
+}
 
-			count := 1
-			for k := range line.Host {
-				split := " "
-				if count == len(line.Host) {
-					split = ""
-				}
-				_, err := fmt.Fprint(file, k, split)
-				if err != nil {
-					return err
-				}
-				count++
+// remove deletes host from every entry line in memory, dropping lines that
+// are left without hosts, and reports whether anything was removed.
+func (h *HostsEdit) remove(host string) bool {
+	removed := false
+	for _, line := range h.Lines {
+		if line.IsComment || line.UndefinedRowsRawStr != "" {
+			continue
+		}
+		if line.RemoveHost(host) {
+			removed = true
+			if len(line.hosts) == 0 {
+				line.IsDelete = true
 			}
 		}
+	}
+	if !removed {
+		return false
+	}
 
-		_, err := fmt.Fprint(file, "\n")
-		if err != nil {
-			return err
+	h.dropDeleted()
+	return true
+}
+
+// dropDeleted removes the lines marked with IsDelete.
+func (h *HostsEdit) dropDeleted() {
+	var updatedLines []*Line
+	for _, line := range h.Lines {
+		if !line.IsDelete {
+			updatedLines = append(updatedLines, line)
 		}
 	}
 
-	return nil
+	h.Lines = updatedLines
+}
+
+// ReplaceIP changes every entry that uses oldIP to use newIP instead and
+// returns the number of lines changed. The file is only saved if a line changed.
+func (h *HostsEdit) ReplaceIP(oldIP, newIP string) (int, error) {
+	if h.readOnly {
+		return 0, ErrReadOnly
+	}
+	if net.ParseIP(newIP) == nil {
+		return 0, errors.New("invalid ip")
+	}
+
+	oldAddr, _ := netip.ParseAddr(oldIP)
+	count := 0
+	for _, line := range h.Lines {
+		if line.IsComment || line.UndefinedRowsRawStr != "" {
+			continue
+		}
+		if line.IP != "" && line.hasAddr(oldIP, oldAddr) {
+			for _, host := range line.hosts {
+				h.recordHistory(OpSet, host, newIP, line.IP)
+			}
+			line.setIP(newIP)
+			line.touch()
+			count++
+		}
+	}
+
+	if count == 0 {
+		return 0, nil
+	}
+
+	err := h.saveToFile(operation{name: "ReplaceIP", ip: newIP})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
 }
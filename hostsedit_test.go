@@ -17,9 +17,13 @@
 package hostedit
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 // 创建测试用的hosts文件
@@ -62,6 +66,24 @@ func TestNew(t *testing.T) {
 	}
 }
 
+// 测试超过64KB的行也能解析，模糊测试发现的问题
+func TestNewLongLine(t *testing.T) {
+	longHost := strings.Repeat("a", 70000)
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n10.0.0.1 " + longHost + "\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, err := New(filePath, false)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if ip, _ := hostsEdit.Get(longHost); ip != "10.0.0.1" {
+		t.Errorf("Get(long host) = %q; want %q", ip, "10.0.0.1")
+	}
+}
+
 // 测试Get方法
 func TestGet(t *testing.T) {
 	hostsContent := `
@@ -88,6 +110,149 @@ func TestGet(t *testing.T) {
 	}
 }
 
+// 测试Contains方法
+func TestContains(t *testing.T) {
+	hostsContent := `
+127.0.0.1 localhost
+::1 localhost
+# 10.0.0.1 disabled.test
+`
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+
+	tests := []struct {
+		host string
+		ip   string
+		want bool
+	}{
+		{"localhost", "127.0.0.1", true},
+		{"localhost", "::1", true},
+		{"LocalHost", "127.0.0.1", true},
+		{"localhost", "10.0.0.1", false},
+		{"disabled.test", "10.0.0.1", false},
+		{"missing.test", "127.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := hostsEdit.Contains(tt.host, tt.ip); got != tt.want {
+			t.Errorf("Contains(%s, %s) = %v; want %v", tt.host, tt.ip, got, tt.want)
+		}
+	}
+}
+
+// 测试Edit把新主机加入写法不同但地址相同的行
+func TestEditMergesEqualIP(t *testing.T) {
+	filePath, err := createTestHostsFile("::1 a\n10.0.0.1 c\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	err = hostsEdit.Edit("b", "0::1")
+	if err != nil {
+		t.Fatalf("Edit(b, 0::1) failed with error: %v", err)
+	}
+	// 直接修改IP字段后仍按新的地址比较
+	hostsEdit.Lines[1].IP = "::2"
+	err = hostsEdit.Edit("d", "0:0::2")
+	if err != nil {
+		t.Fatalf("Edit(d, 0:0::2) failed with error: %v", err)
+	}
+
+	want := "::1 a b\n::2 c d\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+}
+
+// 测试IsLocalhostMapped和IsBlackholed方法
+func TestIsLocalhostMapped(t *testing.T) {
+	hostsContent := `
+127.0.0.1 localhost
+127.1.2.3 dev.myapp.test
+10.0.0.1 remote.test dev.myapp.test
+::1 v6.test
+::ffff:127.0.0.1 mapped.test
+0.0.0.0 ads.test
+:: v6ads.test
+# 127.0.0.1 disabled.test
+`
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	tests := []struct {
+		host       string
+		loopback   bool
+		blackholed bool
+	}{
+		{"localhost", true, false},
+		{"dev.myapp.test", true, false},
+		{"remote.test", false, false},
+		{"v6.test", true, false},
+		{"mapped.test", true, false},
+		{"ads.test", false, true},
+		{"v6ads.test", false, true},
+		{"disabled.test", false, false},
+		{"missing.test", false, false},
+	}
+	for _, tt := range tests {
+		if got := hostsEdit.IsLocalhostMapped(tt.host); got != tt.loopback {
+			t.Errorf("IsLocalhostMapped(%s) = %v; want %v", tt.host, got, tt.loopback)
+		}
+		if got := hostsEdit.IsBlackholed(tt.host); got != tt.blackholed {
+			t.Errorf("IsBlackholed(%s) = %v; want %v", tt.host, got, tt.blackholed)
+		}
+	}
+}
+
+// 测试GetFamily方法
+func TestGetFamily(t *testing.T) {
+	hostsContent := `
+::1 myapp.local
+127.0.0.1 myapp.local localhost
+::1 ipv6host
+# 10.0.0.1 ipv6host
+`
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+
+	tests := []struct {
+		host   string
+		family Family
+		ip     string
+		exists bool
+	}{
+		{"myapp.local", IPv4, "127.0.0.1", true},
+		{"myapp.local", IPv6, "::1", true},
+		{"localhost", IPv4, "127.0.0.1", true},
+		{"localhost", IPv6, "", false},
+		{"ipv6host", IPv6, "::1", true},
+		{"ipv6host", IPv4, "", false},
+		{"nonexistent", IPv4, "", false},
+	}
+	for _, tt := range tests {
+		ip, exists := hostsEdit.GetFamily(tt.host, tt.family)
+		if ip != tt.ip || exists != tt.exists {
+			t.Errorf("GetFamily(%s, %v) = %v, %v; want %v, %v", tt.host, tt.family, ip, exists, tt.ip, tt.exists)
+		}
+	}
+}
+
 // 测试Exists方法
 func TestExists(t *testing.T) {
 	hostsContent := `
@@ -116,6 +281,149 @@ func TestExists(t *testing.T) {
 	}
 }
 
+// 测试GetLineForHost方法
+func TestGetLineForHost(t *testing.T) {
+	hostsContent := `
+127.0.0.1 localhost myapp.local
+# 10.0.0.1 disabled.test
+`
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+
+	line := hostsEdit.GetLineForHost("myapp.local")
+	if line == nil || line.IP != "127.0.0.1" {
+		t.Fatalf("GetLineForHost(myapp.local) = %v; want line with IP 127.0.0.1", line)
+	}
+	if !line.HasHost("localhost") {
+		t.Errorf("GetLineForHost(myapp.local) returned line without localhost")
+	}
+
+	if line := hostsEdit.GetLineForHost("disabled.test"); line != nil {
+		t.Errorf("GetLineForHost(disabled.test) = %v; want nil", line)
+	}
+	if line := hostsEdit.GetLineForHost("nonexistent"); line != nil {
+		t.Errorf("GetLineForHost(nonexistent) = %v; want nil", line)
+	}
+}
+
+// 测试GetLineForIP方法
+func TestGetLineForIP(t *testing.T) {
+	hostsContent := `
+# 10.0.0.1 disabled.test
+10.0.0.1 api.test
+10.0.0.1 web.test
+`
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+
+	line := hostsEdit.GetLineForIP("10.0.0.1")
+	if line == nil {
+		t.Fatalf("GetLineForIP(10.0.0.1) = nil; want line")
+	}
+	if !line.HasHost("api.test") {
+		t.Errorf("GetLineForIP(10.0.0.1) returned %v; want first active line", line)
+	}
+
+	if line := hostsEdit.GetLineForIP("10.0.0.2"); line != nil {
+		t.Errorf("GetLineForIP(10.0.0.2) = %v; want nil", line)
+	}
+}
+
+// 测试GetAllLinesForIP方法
+func TestGetAllLinesForIP(t *testing.T) {
+	hostsContent := `
+10.0.0.1 api.test
+127.0.0.1 localhost
+10.0.0.1 web.test
+# 10.0.0.1 disabled.test
+`
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+
+	lines := hostsEdit.GetAllLinesForIP("10.0.0.1")
+	if len(lines) != 2 || !lines[0].HasHost("api.test") || !lines[1].HasHost("web.test") {
+		t.Errorf("GetAllLinesForIP(10.0.0.1) = %v; want api.test and web.test lines", lines)
+	}
+
+	lines = hostsEdit.GetAllLinesForIP("10.0.0.2")
+	if lines == nil || len(lines) != 0 {
+		t.Errorf("GetAllLinesForIP(10.0.0.2) = %#v; want empty slice", lines)
+	}
+}
+
+// 测试GetActiveLines和GetInactiveLines方法
+func TestGetActiveLines(t *testing.T) {
+	hostsContent := `
+127.0.0.1 localhost
+# Comment line
+# 10.0.0.1 disabled.test
+garbage
+::1 ipv6host
+`
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+
+	active := hostsEdit.GetActiveLines()
+	if len(active) != 2 || active[0].IP != "127.0.0.1" || active[1].IP != "::1" {
+		t.Errorf("GetActiveLines() returned %d lines; want the 127.0.0.1 and ::1 lines", len(active))
+	}
+	inactive := hostsEdit.GetInactiveLines()
+	if len(inactive) != 3 {
+		t.Errorf("GetInactiveLines() returned %d lines; want 3", len(inactive))
+	}
+
+	// 返回的是副本
+	active[0].AddHost("copy.test")
+	if hostsEdit.Exists("copy.test") {
+		t.Errorf("changing a line returned by GetActiveLines changed the hosts file")
+	}
+}
+
+// 测试GroupByIP方法
+func TestGroupByIP(t *testing.T) {
+	hostsContent := `
+10.0.0.1 web.test api.test
+127.0.0.1 localhost
+10.0.0.1 db.test api.test
+# 10.0.0.1 disabled.test
+`
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+
+	want := map[string][]string{
+		"10.0.0.1":  {"api.test", "db.test", "web.test"},
+		"127.0.0.1": {"localhost"},
+	}
+	if got := hostsEdit.GroupByIP(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupByIP() = %v; want %v", got, want)
+	}
+}
+
 // 测试Edit方法
 func TestEdit(t *testing.T) {
 	hostsContent := `
@@ -155,6 +463,68 @@ func TestEdit(t *testing.T) {
 	}
 }
 
+// 测试Edit清理在多行中重复出现的主机
+func TestEditDuplicates(t *testing.T) {
+	hostsContent := `
+127.0.0.1 localhost
+10.0.0.1 web.test api.test
+10.0.0.2 api.test
+10.0.0.3 api.test db.test
+`
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	err = hostsEdit.Edit("api.test", "10.0.0.9")
+	if err != nil {
+		t.Fatalf("Edit(api.test, 10.0.0.9) failed with error: %v", err)
+	}
+
+	updatedHostsEdit, _ := New(filePath, false)
+	if ip, _ := updatedHostsEdit.Get("api.test"); ip != "10.0.0.9" {
+		t.Errorf("Get(api.test) = %v; want 10.0.0.9", ip)
+	}
+	count := 0
+	for _, line := range updatedHostsEdit.Lines {
+		if line.HasHost("api.test") {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("api.test appears on %d lines; want 1", count)
+	}
+	for _, host := range []string{"web.test", "db.test", "localhost"} {
+		if !updatedHostsEdit.Exists(host) {
+			t.Errorf("Edit removed unrelated host %s", host)
+		}
+	}
+	// 只剩api.test的行应被删除
+	if len(updatedHostsEdit.Lines) != 4 {
+		t.Errorf("Expected 4 lines, got %d", len(updatedHostsEdit.Lines))
+	}
+
+	// 第一处已是目标IP时也要清理后面的重复
+	err = os.WriteFile(filePath, []byte("10.0.0.1 api.test\n10.0.0.2 api.test\n10.0.0.3 api.test\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostsEdit, _ = New(filePath, false)
+	err = hostsEdit.Edit("api.test", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Edit(api.test, 10.0.0.1) failed with error: %v", err)
+	}
+	contentBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read hosts file: %v", err)
+	}
+	if string(contentBytes) != "10.0.0.1 api.test\n" {
+		t.Errorf("saved content = %q; want only the first line", contentBytes)
+	}
+}
+
 // TestDelete 测试Delete方法
 func TestDelete(t *testing.T) {
 	// 创建一个测试用的hosts文件内容
@@ -207,3 +577,663 @@ func TestDelete(t *testing.T) {
 		t.Errorf("文件不包含预期的其他主机名记录")
 	}
 }
+
+// 测试ReplaceIP方法
+func TestReplaceIP(t *testing.T) {
+	hostsContent := `
+10.0.0.1 api.test
+10.0.0.1 web.test db.test
+127.0.0.1 localhost
+# 10.0.0.1 disabled.test
+`
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+
+	if _, err := hostsEdit.ReplaceIP("10.0.0.1", "not-an-ip"); err == nil {
+		t.Errorf("ReplaceIP() with invalid ip should fail")
+	}
+
+	count, err := hostsEdit.ReplaceIP("10.0.0.1", "10.0.0.2")
+	if err != nil {
+		t.Fatalf("ReplaceIP() error = %v", err)
+	}
+	if count != 2 {
+		t.Errorf("ReplaceIP() = %d; want 2", count)
+	}
+
+	updatedHostsEdit, _ := New(filePath, false)
+	for _, host := range []string{"api.test", "web.test", "db.test"} {
+		ip, _ := updatedHostsEdit.Get(host)
+		if ip != "10.0.0.2" {
+			t.Errorf("Get(%s) = %v; want 10.0.0.2", host, ip)
+		}
+	}
+	if ip, _ := updatedHostsEdit.Get("localhost"); ip != "127.0.0.1" {
+		t.Errorf("ReplaceIP changed unrelated line, got IP %v", ip)
+	}
+}
+
+// 测试InsertAppend策略只追加新行
+func TestEditInsertAppend(t *testing.T) {
+	// 原文件末尾没有换行符，且格式不规范
+	hostsContent := "127.0.0.1   localhost\n# Comment line\n::1\tipv6host"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false, WithInsertPolicy(InsertAppend))
+
+	err = hostsEdit.Edit("newhost", "127.0.0.2")
+	if err != nil {
+		t.Fatalf("Edit(newhost, 127.0.0.2) failed with error: %v", err)
+	}
+	err = hostsEdit.Edit("otherhost", "127.0.0.3")
+	if err != nil {
+		t.Fatalf("Edit(otherhost, 127.0.0.3) failed with error: %v", err)
+	}
+
+	contentBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read hosts file: %v", err)
+	}
+	want := hostsContent + "\n127.0.0.2 newhost\n127.0.0.3 otherhost\n"
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+
+	// 修改已有行时回退到整体重写
+	err = hostsEdit.Edit("localhost", "127.0.0.9")
+	if err != nil {
+		t.Fatalf("Edit(localhost, 127.0.0.9) failed with error: %v", err)
+	}
+	updatedHostsEdit, _ := New(filePath, false)
+	if ip, _ := updatedHostsEdit.Get("localhost"); ip != "127.0.0.9" {
+		t.Errorf("Edit failed to update localhost, got IP %v", ip)
+	}
+	if len(updatedHostsEdit.Lines) != 5 {
+		t.Errorf("Expected 5 lines, got %d", len(updatedHostsEdit.Lines))
+	}
+}
+
+// 测试InsertGroupByDomain按域名分组插入
+func TestEditInsertGroupByDomain(t *testing.T) {
+	hostsContent := `127.0.0.1 localhost
+10.0.0.1 example.com
+10.0.1.1 other.org
+10.0.0.2 www.example.com
+10.0.1.2 api.other.org
+# footer
+`
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false, WithInsertPolicy(InsertGroupByDomain))
+
+	edits := []struct{ host, ip string }{
+		{"api.staging.example.com", "10.0.0.3"},
+		{"db.other.org", "10.0.1.3"},
+		{"web.staging.example.com", "10.0.0.4"},
+		{"notexample.com", "10.0.2.1"},
+		{"unrelated.net", "10.0.3.1"},
+	}
+	for _, e := range edits {
+		err = hostsEdit.Edit(e.host, e.ip)
+		if err != nil {
+			t.Fatalf("Edit(%s, %s) failed with error: %v", e.host, e.ip, err)
+		}
+	}
+
+	want := `10.0.3.1 unrelated.net
+10.0.2.1 notexample.com
+127.0.0.1 localhost
+10.0.0.1 example.com
+10.0.1.1 other.org
+10.0.0.2 www.example.com
+10.0.0.3 api.staging.example.com
+10.0.0.4 web.staging.example.com
+10.0.1.2 api.other.org
+10.0.1.3 db.other.org
+# footer
+`
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+}
+
+// 测试文件在外部被修改后不再使用追加
+func TestEditInsertAppendFileChanged(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false, WithInsertPolicy(InsertAppend))
+
+	err = os.WriteFile(filePath, []byte("127.0.0.1 localhost\n10.0.0.1 external.test\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = hostsEdit.Edit("newhost", "127.0.0.2")
+	if err != nil {
+		t.Fatalf("Edit(newhost, 127.0.0.2) failed with error: %v", err)
+	}
+
+	contentBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read hosts file: %v", err)
+	}
+	want := "127.0.0.1 localhost\n127.0.0.2 newhost\n"
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+}
+
+// 测试删除不存在的主机时不写文件
+func TestDeleteNoop(t *testing.T) {
+	hostsContent := "127.0.0.1   localhost\n# Comment line\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	err = os.Chtimes(filePath, past, past)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hostsEdit, _ := New(filePath, false)
+	err = hostsEdit.Delete("nonexistent.com")
+	if err != nil {
+		t.Errorf("Delete(nonexistent.com) error = %v", err)
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(past) {
+		t.Errorf("Delete(nonexistent.com) changed mtime from %v to %v", past, info.ModTime())
+	}
+	contentBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read hosts file: %v", err)
+	}
+	if string(contentBytes) != hostsContent {
+		t.Errorf("Delete(nonexistent.com) changed content to %q", contentBytes)
+	}
+}
+
+// 测试删除出现在多行中的主机
+func TestDeleteAllOccurrences(t *testing.T) {
+	hostsContent := `
+10.0.0.1 api.test web.test
+10.0.0.2 api.test
+`
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	err = hostsEdit.Delete("api.test")
+	if err != nil {
+		t.Fatalf("Delete(api.test) error = %v", err)
+	}
+
+	updatedHostsEdit, _ := New(filePath, false)
+	if updatedHostsEdit.Exists("api.test") {
+		t.Errorf("Delete(api.test) left a duplicate behind")
+	}
+	if len(updatedHostsEdit.Lines) != 1 || !updatedHostsEdit.Exists("web.test") {
+		t.Errorf("Delete(api.test) removed the wrong lines: %v", updatedHostsEdit.Lines)
+	}
+}
+
+// 测试带UTF-8 BOM的文件
+func TestNewBOM(t *testing.T) {
+	filePath, err := createTestHostsFile("\xef\xbb\xbf127.0.0.1 localhost\n::1 ipv6host\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, err := New(filePath, true)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ip, exists := hostsEdit.Get("localhost")
+	if !exists || ip != "127.0.0.1" {
+		t.Errorf("Get(localhost) = %v, %v; want %v, %v", ip, exists, "127.0.0.1", true)
+	}
+}
+
+// 测试WithReuseDisabled重新启用被注释的条目
+func TestEditReuseDisabled(t *testing.T) {
+	hostsContent := `127.0.0.1 localhost
+# 10.0.0.1 api.test
+# 10.0.0.2 web.test db.test
+`
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false, WithReuseDisabled())
+
+	// 相同IP
+	err = hostsEdit.Edit("api.test", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Edit(api.test, 10.0.0.1) failed with error: %v", err)
+	}
+	// 不同IP，且被注释的行中还有其他主机
+	err = hostsEdit.Edit("db.test", "10.0.0.3")
+	if err != nil {
+		t.Fatalf("Edit(db.test, 10.0.0.3) failed with error: %v", err)
+	}
+
+	contentBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read hosts file: %v", err)
+	}
+	want := `127.0.0.1 localhost
+10.0.0.1 api.test
+10.0.0.3 db.test
+# 10.0.0.2 web.test
+`
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+
+	// 不使用该选项时行为不变
+	err = os.WriteFile(filePath, []byte(hostsContent), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostsEdit, _ = New(filePath, false)
+	err = hostsEdit.Edit("api.test", "10.0.0.5")
+	if err != nil {
+		t.Fatalf("Edit(api.test, 10.0.0.5) failed with error: %v", err)
+	}
+	if len(hostsEdit.Lines) != 4 || !hostsEdit.Lines[2].IsDisabled() {
+		t.Errorf("Edit without WithReuseDisabled changed the disabled entry")
+	}
+}
+
+// 测试WithEncoding选项
+func TestWithEncoding(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false, WithEncoding(EncodingUTF8BOM))
+	err = hostsEdit.Edit("newhost", "127.0.0.2")
+	if err != nil {
+		t.Fatalf("Edit(newhost, 127.0.0.2) failed with error: %v", err)
+	}
+	contentBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read hosts file: %v", err)
+	}
+	want := "\xef\xbb\xbf127.0.0.2 newhost\n127.0.0.1 localhost\n"
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+
+	// 默认不写BOM，即使原文件有
+	hostsEdit, _ = New(filePath, false)
+	err = hostsEdit.Edit("newhost", "127.0.0.3")
+	if err != nil {
+		t.Fatalf("Edit(newhost, 127.0.0.3) failed with error: %v", err)
+	}
+	contentBytes, err = os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read hosts file: %v", err)
+	}
+	if strings.HasPrefix(string(contentBytes), "\xef\xbb\xbf") {
+		t.Errorf("saved content = %q; want no BOM", contentBytes)
+	}
+}
+
+// 测试DeleteLine方法
+func TestDeleteLine(t *testing.T) {
+	hostsContent := "# stale comment\n127.0.0.1 localhost\nnot an entry\n10.0.0.1 a.test\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	// 从后往前删除，前面的索引保持不变
+	err = hostsEdit.DeleteLine(2)
+	if err != nil {
+		t.Fatalf("DeleteLine(2) error = %v", err)
+	}
+	err = hostsEdit.DeleteLine(0)
+	if err != nil {
+		t.Fatalf("DeleteLine(0) error = %v", err)
+	}
+
+	want := "127.0.0.1 localhost\n10.0.0.1 a.test\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("DeleteLine() wrote %q; want %q", contentBytes, want)
+	}
+
+	for _, i := range []int{-1, 2} {
+		err = hostsEdit.DeleteLine(i)
+		if !errors.Is(err, ErrIndexOutOfRange) {
+			t.Errorf("DeleteLine(%d) error = %v; want ErrIndexOutOfRange", i, err)
+		}
+	}
+	if len(hostsEdit.Lines) != 2 {
+		t.Errorf("len(Lines) = %d after out of range DeleteLine; want 2", len(hostsEdit.Lines))
+	}
+}
+
+// 测试修改带行尾注释的条目时保留注释
+func TestEditKeepsInlineComment(t *testing.T) {
+	hostsContent := "10.0.0.1 api.test # added by myapp\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, true)
+	err = hostsEdit.Edit("api.test", "10.0.0.2")
+	if err != nil {
+		t.Fatalf("Edit(api.test, 10.0.0.2) failed with error: %v", err)
+	}
+
+	want := "10.0.0.2 api.test # added by myapp\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+}
+
+// 测试LineAt和Len方法
+func TestLineAt(t *testing.T) {
+	hostsContent := "# comment\n10.0.0.1 a.test b.test\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	if hostsEdit.Len() != 2 {
+		t.Fatalf("Len() = %d; want 2", hostsEdit.Len())
+	}
+	line, err := hostsEdit.LineAt(1)
+	if err != nil {
+		t.Fatalf("LineAt(1) error = %v", err)
+	}
+	if line.IP != "10.0.0.1" || !reflect.DeepEqual(line.Hosts(), []string{"a.test", "b.test"}) {
+		t.Errorf("LineAt(1) = %v %v; want 10.0.0.1 [a.test b.test]", line.IP, line.Hosts())
+	}
+
+	// 修改副本不影响原来的行
+	line.IP = "10.0.0.9"
+	line.RemoveHost("a.test")
+	if ip, _ := hostsEdit.Get("a.test"); ip != "10.0.0.1" {
+		t.Errorf("Get(a.test) = %q after changing the copy; want 10.0.0.1", ip)
+	}
+
+	_, err = hostsEdit.LineAt(2)
+	if !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("LineAt(2) error = %v; want ErrIndexOutOfRange", err)
+	}
+}
+
+// 测试DeleteFunc方法
+func TestDeleteFunc(t *testing.T) {
+	hostsContent := "127.0.0.1 localhost\n0.0.0.0 ads.test tracker.test\n# 0.0.0.0 disabled.test\n10.0.0.1 api.test\n0.0.0.0 only.test\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	var visited []string
+	removed, err := hostsEdit.DeleteFunc(func(ip, host string) bool {
+		visited = append(visited, ip+" "+host)
+		// 回调期间不能看到已修改的状态
+		if !hostsEdit.Exists("ads.test") {
+			t.Errorf("DeleteFunc() removed a host before visiting every host")
+		}
+		return ip == "0.0.0.0" && host != "tracker.test"
+	})
+	if removed != 2 || err != nil {
+		t.Fatalf("DeleteFunc() = %d, %v; want 2, nil", removed, err)
+	}
+	wantVisited := []string{"127.0.0.1 localhost", "0.0.0.0 ads.test", "0.0.0.0 tracker.test", "10.0.0.1 api.test", "0.0.0.0 only.test"}
+	if !reflect.DeepEqual(visited, wantVisited) {
+		t.Errorf("DeleteFunc() visited %v; want %v", visited, wantVisited)
+	}
+
+	want := "127.0.0.1 localhost\n0.0.0.0 tracker.test\n# 0.0.0.0 disabled.test\n10.0.0.1 api.test\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+
+	removed, err = hostsEdit.DeleteFunc(func(ip, host string) bool { return false })
+	if removed != 0 || err != nil {
+		t.Errorf("DeleteFunc(none) = %d, %v; want 0, nil", removed, err)
+	}
+}
+
+// 测试SwapLines方法
+func TestSwapLines(t *testing.T) {
+	hostsContent := "10.0.0.1 a.test\n# comment\n10.0.0.2 a.test b.test\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	err = hostsEdit.SwapLines(0, 2)
+	if err != nil {
+		t.Fatalf("SwapLines(0, 2) error = %v", err)
+	}
+	want := "10.0.0.2 a.test b.test\n# comment\n10.0.0.1 a.test\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+	if ip, _ := hostsEdit.Get("a.test"); ip != "10.0.0.2" {
+		t.Errorf("Get(a.test) = %s after SwapLines; want 10.0.0.2", ip)
+	}
+
+	for _, idx := range [][2]int{{0, 3}, {-1, 1}} {
+		err = hostsEdit.SwapLines(idx[0], idx[1])
+		if !errors.Is(err, ErrIndexOutOfRange) {
+			t.Errorf("SwapLines(%d, %d) error = %v; want ErrIndexOutOfRange", idx[0], idx[1], err)
+		}
+	}
+	contentBytes, _ = os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("content after failed SwapLines = %q; want %q", contentBytes, want)
+	}
+}
+
+// 测试GetLineIndex方法
+func TestGetLineIndex(t *testing.T) {
+	hostsContent := "# 10.0.0.1 a.test\n10.0.0.2 b.test\n10.0.0.3 a.test c.test\n10.0.0.4 a.test\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	tests := []struct {
+		host string
+		want int
+	}{
+		{"a.test", 2},
+		{"C.test", 2},
+		{"b.test", 1},
+		{"missing.test", -1},
+	}
+	for _, tt := range tests {
+		if got := hostsEdit.GetLineIndex(tt.host); got != tt.want {
+			t.Errorf("GetLineIndex(%s) = %d; want %d", tt.host, got, tt.want)
+		}
+	}
+}
+
+// 测试GetInlineComment方法
+func TestGetInlineComment(t *testing.T) {
+	hostsContent := "# 10.0.0.1 api.test # disabled\n10.0.0.2 api.test web.test # added by myapp\n10.0.0.3 plain.test\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"api.test", "added by myapp"},
+		{"web.test", "added by myapp"},
+		{"plain.test", ""},
+		{"missing.test", ""},
+	}
+	for _, tt := range tests {
+		if got := hostsEdit.GetInlineComment(tt.host); got != tt.want {
+			t.Errorf("GetInlineComment(%s) = %q; want %q", tt.host, got, tt.want)
+		}
+	}
+}
+
+// 测试SetInlineComment方法
+func TestSetInlineComment(t *testing.T) {
+	hostsContent := "10.0.0.1 api.test web.test # old\n10.0.0.2 db.test\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	err = hostsEdit.SetInlineComment("web.test", "added by myapp")
+	if err != nil {
+		t.Fatalf("SetInlineComment(web.test) error = %v", err)
+	}
+	err = hostsEdit.SetInlineComment("db.test", "primary")
+	if err != nil {
+		t.Fatalf("SetInlineComment(db.test) error = %v", err)
+	}
+	want := "10.0.0.1 api.test web.test # added by myapp\n10.0.0.2 db.test # primary\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+
+	// 空注释清除行尾注释
+	err = hostsEdit.SetInlineComment("api.test", "")
+	if err != nil {
+		t.Fatalf("SetInlineComment(api.test, \"\") error = %v", err)
+	}
+	want = "10.0.0.1 api.test web.test\n10.0.0.2 db.test # primary\n"
+	contentBytes, _ = os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+
+	err = hostsEdit.SetInlineComment("missing.test", "x")
+	if !errors.Is(err, ErrHostNotFound) {
+		t.Errorf("SetInlineComment(missing.test) error = %v; want ErrHostNotFound", err)
+	}
+	if err = hostsEdit.SetInlineComment("db.test", "a\nb"); err == nil {
+		t.Errorf("SetInlineComment() with a line break succeeded")
+	}
+}
+
+// 测试NewOrCreate在文件不存在时创建文件
+func TestNewOrCreate(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "hosts")
+
+	hostsEdit, err := NewOrCreate(filePath, WithHeader([]string{"Managed by myapp"}), WithCreateMode(0o600))
+	if err != nil {
+		t.Fatalf("NewOrCreate() error = %v", err)
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatalf("NewOrCreate() did not create the file: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("created file mode = %v; want 0600", info.Mode().Perm())
+	}
+	err = hostsEdit.Edit("a.test", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Edit(a.test, 10.0.0.1) failed with error: %v", err)
+	}
+	want := "# Managed by myapp\n10.0.0.1 a.test\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+
+	// 已存在的文件直接加载，不会被覆盖
+	hostsEdit, err = NewOrCreate(filePath)
+	if err != nil || !hostsEdit.Exists("a.test") {
+		t.Errorf("NewOrCreate() of an existing file = %v; want a.test loaded", err)
+	}
+
+	// 父目录不存在时给出明确的错误
+	_, err = NewOrCreate(filepath.Join(dir, "missing", "hosts"))
+	if !errors.Is(err, os.ErrNotExist) || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("NewOrCreate() in a missing directory error = %v", err)
+	}
+}
+
+// 测试并发调用NewOrCreate时只有一个创建者
+func TestNewOrCreateConcurrent(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "hosts")
+
+	errs := make(chan error, 8)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			_, err := NewOrCreate(filePath, WithHeader([]string{"header"}))
+			errs <- err
+		}()
+	}
+	for i := 0; i < cap(errs); i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("NewOrCreate() error = %v", err)
+		}
+	}
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != "# header\n" {
+		t.Errorf("created content = %q; want a single header", contentBytes)
+	}
+}
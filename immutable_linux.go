@@ -0,0 +1,47 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+//go:build linux
+
+package hostedit
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// fsImmutableFL is FS_IMMUTABLE_FL, the flag of the immutable attribute.
+const fsImmutableFL = 0x10
+
+// fsIocGetflags is FS_IOC_GETFLAGS, _IOR('f', 1, long) with the generic
+// ioctl encoding. Architectures with another encoding fail the ioctl.
+const fsIocGetflags = 2<<30 | unsafe.Sizeof(uintptr(0))<<16 | 'f'<<8 | 1
+
+// isImmutable reports whether the file at path has the immutable attribute.
+// It is best effort and returns false if the flags cannot be read, such as
+// on file systems without attributes. Replaced in tests.
+var isImmutable = func(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	var flags int32
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, file.Fd(), fsIocGetflags, uintptr(unsafe.Pointer(&flags)))
+	return errno == 0 && flags&fsImmutableFL != 0
+}
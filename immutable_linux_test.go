@@ -0,0 +1,55 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+//go:build linux
+
+package hostedit
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// 测试isImmutable读取chattr设置的属性
+func TestIsImmutable(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	if isImmutable(filePath) {
+		t.Errorf("isImmutable() = true for a new file")
+	}
+	if out, err := exec.Command("chattr", "+i", filePath).CombinedOutput(); err != nil {
+		t.Skipf("cannot set the immutable attribute: %v %s", err, out)
+	}
+	defer exec.Command("chattr", "-i", filePath).Run()
+
+	if !isImmutable(filePath) {
+		t.Errorf("isImmutable() = false after chattr +i")
+	}
+	hostsEdit, _ := New(filePath, false)
+	if err := hostsEdit.CheckWritePermission(); !errors.Is(err, ErrImmutableFile) {
+		t.Errorf("CheckWritePermission() of an immutable file error = %v; want ErrImmutableFile", err)
+	}
+	err = hostsEdit.Edit("a.test", "10.0.0.1")
+	if !errors.Is(err, ErrImmutableFile) {
+		t.Errorf("Edit() of an immutable file error = %v; want ErrImmutableFile", err)
+	}
+}
@@ -0,0 +1,65 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+)
+
+// epermFS 模拟写入时返回EPERM的文件系统
+type epermFS struct {
+	osFileSystem
+}
+
+func (epermFS) Create(name string) (*os.File, error) {
+	return nil, &os.PathError{Op: "open", Path: name, Err: syscall.EPERM}
+}
+
+// 不可变文件不能被重命名替换
+func (epermFS) Rename(oldpath, newpath string) error {
+	return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EPERM}
+}
+
+// 测试写入不可变文件失败时给出说明
+func TestImmutableFileError(t *testing.T) {
+	defer func(f func(string) bool) { isImmutable = f }(isImmutable)
+
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	hostsEdit.fs = epermFS{}
+
+	isImmutable = func(string) bool { return true }
+	err = hostsEdit.Edit("a.test", "10.0.0.1")
+	if !errors.Is(err, ErrImmutableFile) || !errors.Is(err, syscall.EPERM) {
+		t.Errorf("Edit() error = %v; want ErrImmutableFile wrapping EPERM", err)
+	}
+
+	// 没有不可变属性时保留原来的错误
+	isImmutable = func(string) bool { return false }
+	err = hostsEdit.Edit("b.test", "10.0.0.2")
+	if errors.Is(err, ErrImmutableFile) || !errors.Is(err, syscall.EPERM) {
+		t.Errorf("Edit() error = %v; want the original EPERM", err)
+	}
+}
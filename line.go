@@ -0,0 +1,639 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"maps"
+	"net/netip"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// indexThreshold is the number of hosts from which a line keeps a lookup
+// map next to its host list. Smaller lines are searched linearly.
+const indexThreshold = 8
+
+// Line is one line of a hosts file.
+//
+// The hosts of a line are kept in the order they appear in the file and are
+// accessed with Hosts, HasHost, AddHost and RemoveHost. They replace the
+// Host map of earlier versions, which could not keep that order and is kept
+// only for compatibility. Like the resolvers that read hosts files, these
+// methods ignore the case of ASCII letters when comparing hosts.
+type Line struct {
+	IsComment           bool
+	UndefinedRowsRawStr string
+	IP                  string
+	// Host holds the hosts of the line as a set. It is filled when the line
+	// is loaded and kept up to date by AddHost and RemoveHost. Hosts added
+	// to or deleted from it directly are applied to the line when the file
+	// is saved, the added ones after the existing hosts in sorted order.
+	//
+	// Deprecated: Host does not keep the order of the hosts. Use Hosts,
+	// HasHost, AddHost and RemoveHost instead.
+	Host     map[string]struct{}
+	IsDelete bool
+	// Extra holds the tokens after the hosts of an entry that cannot be host
+	// names, such as options some tools append, starting with the first
+	// such token. They are written back after the hosts and never match a
+	// host.
+	Extra []string
+	// InlineComment is the text after a "#" that follows the hosts of an
+	// entry, as in "127.0.0.1 example.com # added by myapp", without the
+	// "#" and surrounding spaces.
+	InlineComment string
+
+	hosts  []string
+	index  map[string]struct{} // lowercase hosts, only for lines with at least indexThreshold hosts
+	dup    bool                // hosts holds a host more than once, as written in the file
+	addr   netip.Addr          // IP parsed, to compare addresses by value
+	addrOf string              // IP when addr was parsed, to detect direct changes of IP
+
+	indent   string // leading whitespace written before the line
+	src      string // text the line was parsed from, for the positions of parse errors; "" once modified
+	num      int    // 1-based line number in the file it was loaded from, 0 if not loaded
+	raw      string // original text of the line, without the line ending
+	eol      string // original line ending, "" for a last line without one
+	verbatim bool   // raw can be written back as is
+	dirty    bool   // added or modified since the file was last loaded or saved
+}
+
+// ParseLine parses one line of a hosts file the same way New does, for
+// lines that arrive one at a time, such as from a pipe. A trailing line
+// ending is ignored. A blank line is returned as a Line with no fields set,
+// which New skips unless WithPreserveFormatting is used.
+//
+// A line that is neither blank, a comment nor a valid entry is returned with
+// its text in UndefinedRowsRawStr, together with a *ParseError wrapping
+// ErrMalformedLine. Callers that tolerate such lines, as New does unless
+// isParse is set, can use the returned line and ignore the error.
+func ParseLine(s string) (*Line, error) {
+	line := &Line{src: s}
+
+	text, isComment, entries := splitLine(s)
+	line.IsComment = isComment
+	if text.text == "" {
+		return line, nil
+	}
+
+	if n := hostTokens(entries); n > 0 && line.parseIP(entries[0].text) {
+		if _, comment, ok := strings.Cut(text.text, "#"); ok {
+			line.InlineComment = strings.TrimSpace(comment)
+		}
+		// 保留重复的主机，原样写回未修改的行
+		for _, host := range entries[1 : n+1] {
+			if line.HasHost(host.text) {
+				line.dup = true
+			}
+			line.appendHost(host.text)
+		}
+		for _, token := range entries[n+1:] {
+			line.Extra = append(line.Extra, token.text)
+		}
+		return line, nil
+	}
+
+	line.UndefinedRowsRawStr = text.text
+	if line.IsComment {
+		return line, nil
+	}
+	err := &ParseError{Text: text.text, Err: ErrMalformedLine}
+	err.Column, err.Length = line.malformedSpan()
+	return line, err
+}
+
+// token is a field of a line with its byte offset in the line.
+type token struct {
+	text string
+	off  int
+}
+
+// splitLine returns s without surrounding space and, for a comment, without
+// its leading "#", and the fields of that text before any other "#", with
+// their offsets in s.
+func splitLine(s string) (text token, isComment bool, fields []token) {
+	text = trimSpace(s, 0)
+	if strings.HasPrefix(text.text, "#") {
+		isComment = true
+		text = trimSpace(text.text[1:], text.off+1)
+	}
+
+	entryText, _, _ := strings.Cut(text.text, "#")
+	return text, isComment, fieldsAt(entryText, text.off)
+}
+
+// asciiSpace marks the ASCII characters unicode.IsSpace accepts.
+var asciiSpace = [utf8.RuneSelf]bool{'\t': true, '\n': true, '\v': true, '\f': true, '\r': true, ' ': true}
+
+// fieldsAt splits s around space like strings.Fields, giving each field its
+// offset in a line in which s starts at off.
+func fieldsAt(s string, off int) []token {
+	// 先计算字段数，一次分配；与strings.Fields一样优先处理ASCII
+	n := 0
+	wasSpace := true
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			return fieldsAtFunc(s, off)
+		}
+		isSpace := asciiSpace[c]
+		if wasSpace && !isSpace {
+			n++
+		}
+		wasSpace = isSpace
+	}
+
+	fields := make([]token, 0, n)
+	start := -1
+	for i := 0; i < len(s); i++ {
+		if asciiSpace[s[i]] {
+			if start >= 0 {
+				fields = append(fields, token{s[start:i], off + start})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		fields = append(fields, token{s[start:], off + start})
+	}
+	return fields
+}
+
+// fieldsAtFunc is fieldsAt for text that is not only ASCII.
+func fieldsAtFunc(s string, off int) []token {
+	var fields []token
+	start := -1
+	for i, r := range s {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				fields = append(fields, token{s[start:i], off + start})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		fields = append(fields, token{s[start:], off + start})
+	}
+	return fields
+}
+
+// trimSpace returns s without surrounding space, with its offset in a line
+// in which s starts at off.
+func trimSpace(s string, off int) token {
+	t := strings.TrimLeftFunc(s, unicode.IsSpace)
+	return token{strings.TrimRightFunc(t, unicode.IsSpace), off + len(s) - len(t)}
+}
+
+// fieldSpan returns the 1-based column and the length in bytes of field i
+// of the line as it was parsed, where field 0 is the IP address, the hosts
+// follow as written, and then Extra. It returns 0, 0 if the line has been
+// modified since.
+func (l *Line) fieldSpan(i int) (col, length int) {
+	_, _, fields := splitLine(l.src)
+	if i >= len(fields) {
+		return 0, 0
+	}
+	return fields[i].off + 1, len(fields[i].text)
+}
+
+// malformedSpan returns the 1-based column and the length in bytes of what
+// makes a line with UndefinedRowsRawStr malformed: the IP address if it is
+// invalid, the first character of the first host that cannot be in a host
+// name, or else the whole text.
+func (l *Line) malformedSpan() (col, length int) {
+	text, _, fields := splitLine(l.src)
+	switch {
+	case text.text == "":
+		return 0, 0
+	case len(fields) > 0 && !isAddr(fields[0].text):
+		return fields[0].off + 1, len(fields[0].text)
+	case len(fields) > 1 && !isHostToken(fields[1].text):
+		// isHostToken accepts all non-ASCII characters, so the first
+		// character it rejects is a single byte
+		i := strings.IndexFunc(fields[1].text, func(r rune) bool { return !isHostToken(string(r)) })
+		return fields[1].off + i + 1, 1
+	}
+	return text.off + 1, len(text.text)
+}
+
+// parseIP sets the IP address of the line to s and reports whether s is a
+// valid address, leaving the line unchanged if it is not.
+func (l *Line) parseIP(s string) bool {
+	addr, ok := parseAddr(s)
+	if !ok {
+		return false
+	}
+	l.IP, l.addr, l.addrOf = s, addr, s
+	return true
+}
+
+// parseAddr parses an IP address of an entry. Like net.ParseIP, it does not
+// accept zones, as in fe80::1%eth0.
+func parseAddr(s string) (netip.Addr, bool) {
+	addr, err := netip.ParseAddr(s)
+	return addr, err == nil && addr.Zone() == ""
+}
+
+// isAddr reports whether s is an IP address ParseLine accepts.
+func isAddr(s string) bool {
+	_, ok := parseAddr(s)
+	return ok
+}
+
+// setIP sets the IP address of the line and its parsed form.
+func (l *Line) setIP(ip string) {
+	if !l.parseIP(ip) {
+		l.IP, l.addr, l.addrOf = ip, netip.Addr{}, ip
+	}
+}
+
+// hasAddr reports whether the IP address of the line is ip, given with its
+// parsed form addr, comparing addresses by value as sameIP does.
+func (l *Line) hasAddr(ip string, addr netip.Addr) bool {
+	if l.IP == ip {
+		return true
+	}
+	if !addr.IsValid() {
+		return false
+	}
+	if l.addrOf == l.IP {
+		return l.addr == addr
+	}
+	// IP字段被直接修改过
+	a, err := netip.ParseAddr(l.IP)
+	return err == nil && a == addr
+}
+
+// hostTokens returns the number of tokens after the first one that can be
+// host names, up to the first that cannot.
+func hostTokens(entries []token) int {
+	n := 0
+	for _, token := range entries[min(1, len(entries)):] {
+		if !isHostToken(token.text) {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// isHostToken reports whether token only has characters found in host
+// names: ASCII letters, digits, "-", "_" and ".", and any non-ASCII
+// character, for internationalized names. It is more lenient than
+// ValidateHostname, to accept the names resolvers accept in practice.
+func isHostToken(token string) bool {
+	for i := 0; i < len(token); i++ {
+		c := token[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c >= 0x80) {
+			return false
+		}
+	}
+	return true
+}
+
+// RenderOption configures how FormatLine and saving render entry lines.
+type RenderOption func(*renderConfig)
+
+type renderConfig struct {
+	separator string
+	ipWidth   int
+}
+
+// WithSeparator sets the text written between the IP address and each host.
+// The default is a single space.
+func WithSeparator(sep string) RenderOption {
+	return func(c *renderConfig) {
+		c.separator = sep
+	}
+}
+
+// WithIPColumnWidth pads the IP address of entry lines so that the first
+// host starts at the given column, counted from 0, or after a single
+// separator if the address is longer. With a "\t" separator the padding is
+// made of tabs, assuming tab stops every 8 columns.
+func WithIPColumnWidth(width int) RenderOption {
+	return func(c *renderConfig) {
+		c.ipWidth = width
+	}
+}
+
+// FormatLine renders l the way saving does for every line that is not kept
+// verbatim by WithPreserveFormatting, without the line ending.
+func FormatLine(l *Line, opts ...RenderOption) string {
+	var c renderConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return formatLine(l, c)
+}
+
+func formatLine(line *Line, c renderConfig) string {
+	sep := c.separator
+	if sep == "" {
+		sep = " "
+	}
+
+	var b strings.Builder
+	b.WriteString(line.indent)
+	if line.IsComment {
+		if line.UndefinedRowsRawStr == "" && line.IP == "" {
+			return b.String() + "#"
+		}
+		b.WriteString("# ")
+	}
+	col := b.Len()
+	if line.UndefinedRowsRawStr != "" {
+		b.WriteString(line.UndefinedRowsRawStr)
+	} else if line.IP != "" {
+		b.WriteString(line.IP)
+		for i, host := range line.hosts {
+			if i == 0 && c.ipWidth > 0 {
+				writePadding(&b, col+len(line.IP), c.ipWidth, sep)
+			} else {
+				b.WriteString(sep)
+			}
+			b.WriteString(host)
+		}
+		for _, token := range line.Extra {
+			b.WriteString(sep)
+			b.WriteString(token)
+		}
+		if line.InlineComment != "" {
+			b.WriteString(" # ")
+			b.WriteString(line.InlineComment)
+		}
+	}
+	return b.String()
+}
+
+// writePadding writes the padding from column col up to column width, or
+// a single separator if col is already there.
+func writePadding(b *strings.Builder, col, width int, sep string) {
+	if sep != "\t" {
+		if col >= width {
+			b.WriteString(sep)
+			return
+		}
+		b.WriteString(strings.Repeat(" ", width-col))
+		return
+	}
+	for {
+		b.WriteByte('\t')
+		col = (col/8 + 1) * 8
+		if col >= width {
+			return
+		}
+	}
+}
+
+// NewLine returns an entry line mapping hosts to ip.
+func NewLine(ip string, hosts ...string) *Line {
+	l := &Line{dirty: true}
+	l.setIP(ip)
+	for _, host := range hosts {
+		if !l.HasHost(host) {
+			l.appendHost(host)
+		}
+	}
+	return l
+}
+
+// IsDisabled reports whether the line is an entry that has been commented
+// out, such as "# 127.0.0.1 localhost".
+func (l *Line) IsDisabled() bool {
+	return l.IsComment && l.UndefinedRowsRawStr == "" && l.IP != ""
+}
+
+// Hosts returns a copy of the hosts of the line in file order.
+func (l *Line) Hosts() []string {
+	return append([]string(nil), l.hosts...)
+}
+
+// NumHosts returns the number of hosts on the line.
+func (l *Line) NumHosts() int {
+	return len(l.hosts)
+}
+
+// HasHost reports whether host is one of the hosts of the line.
+func (l *Line) HasHost(host string) bool {
+	if l.index != nil {
+		_, ok := l.index[lowerASCII(host)]
+		return ok
+	}
+	for _, v := range l.hosts {
+		if equalFoldASCII(v, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddHost appends host to the line unless it is already there, and reports
+// whether it was added.
+func (l *Line) AddHost(host string) bool {
+	if l.HasHost(host) {
+		return false
+	}
+	l.appendHost(host)
+	l.touch()
+	return true
+}
+
+// RemoveHost removes host from the line and reports whether it was there.
+func (l *Line) RemoveHost(host string) bool {
+	if !l.HasHost(host) {
+		return false
+	}
+	hosts := l.hosts[:0]
+	for _, v := range l.hosts {
+		if !equalFoldASCII(v, host) {
+			hosts = append(hosts, v)
+		}
+	}
+	l.hosts = hosts
+	if l.index != nil {
+		delete(l.index, lowerASCII(host))
+	}
+	for k := range l.Host {
+		if equalFoldASCII(k, host) {
+			delete(l.Host, k)
+		}
+	}
+	l.touch()
+	return true
+}
+
+// onlyHost reports whether host is the only host of the line, possibly
+// repeated.
+func (l *Line) onlyHost(host string) bool {
+	for _, v := range l.hosts {
+		if !equalFoldASCII(v, host) {
+			return false
+		}
+	}
+	return len(l.hosts) > 0
+}
+
+// appendHost adds host to the end of the host list without any checks.
+func (l *Line) appendHost(host string) {
+	l.hosts = append(l.hosts, host)
+	if l.Host == nil {
+		l.Host = make(map[string]struct{}, 1)
+	}
+	l.Host[host] = struct{}{}
+	if l.index != nil {
+		l.index[lowerASCII(host)] = struct{}{}
+	} else if len(l.hosts) >= indexThreshold {
+		l.index = make(map[string]struct{}, len(l.hosts))
+		for _, v := range l.hosts {
+			l.index[lowerASCII(v)] = struct{}{}
+		}
+	}
+}
+
+// setHosts replaces the host list with hosts, dropping repeated hosts.
+// The slice is used directly instead of being copied.
+func (l *Line) setHosts(hosts []string) {
+	l.hosts = hosts[:0]
+	l.index = nil
+	l.Host = nil
+	l.dup = false
+	for _, v := range hosts {
+		if !l.HasHost(v) {
+			l.appendHost(v)
+		}
+	}
+}
+
+// syncHostField applies the changes made directly to the deprecated Host
+// field to the host list, and reports whether there were any. A nil Host is
+// filled from the host list.
+func (l *Line) syncHostField() bool {
+	if l.Host == nil {
+		for _, v := range l.hosts {
+			if l.Host == nil {
+				l.Host = make(map[string]struct{}, len(l.hosts))
+			}
+			l.Host[v] = struct{}{}
+		}
+		return false
+	}
+
+	changed := false
+	var hosts []string
+	for _, v := range l.hosts {
+		if _, ok := l.Host[v]; ok {
+			hosts = append(hosts, v)
+		} else {
+			changed = true
+		}
+	}
+	var added []string
+	for k := range l.Host {
+		if !l.HasHost(k) {
+			added = append(added, k)
+		}
+	}
+	if !changed && len(added) == 0 {
+		return false
+	}
+	// map无序，按排序后的顺序添加新的主机
+	sort.Strings(added)
+	l.setHosts(append(hosts, added...))
+	l.touch()
+	return true
+}
+
+// clone returns a deep copy of the line.
+func (l *Line) clone() *Line {
+	c := *l
+	c.hosts = append([]string(nil), l.hosts...)
+	if l.Host != nil {
+		c.Host = maps.Clone(l.Host)
+	}
+	if l.Extra != nil {
+		c.Extra = append([]string(nil), l.Extra...)
+	}
+	if l.index != nil {
+		c.index = make(map[string]struct{}, len(l.index))
+		for k := range l.index {
+			c.index[k] = struct{}{}
+		}
+	}
+	return &c
+}
+
+// cloneLines returns a deep copy of lines. The copies and their hosts are
+// allocated in two blocks, which keeps copying a large file cheap; the host
+// slices are capped so that adding a host to one copy never writes into the
+// next one.
+func cloneLines(lines []*Line) []*Line {
+	n := 0
+	for _, line := range lines {
+		n += len(line.hosts)
+	}
+	clones := make([]*Line, len(lines))
+	block := make([]Line, len(lines))
+	hosts := make([]string, 0, n)
+	for i, line := range lines {
+		c := &block[i]
+		*c = *line
+		start := len(hosts)
+		hosts = append(hosts, line.hosts...)
+		c.hosts = hosts[start:len(hosts):len(hosts)]
+		if line.hosts == nil {
+			c.hosts = nil
+		}
+		if line.Extra != nil {
+			c.Extra = append([]string(nil), line.Extra...)
+		}
+		if line.Host != nil {
+			c.Host = maps.Clone(line.Host)
+		}
+		if line.index != nil {
+			c.index = make(map[string]struct{}, len(line.index))
+			for k := range line.index {
+				c.index[k] = struct{}{}
+			}
+		}
+		clones[i] = c
+	}
+	return clones
+}
+
+// isBlank reports whether the line is empty.
+func (l *Line) isBlank() bool {
+	return !l.IsComment && l.UndefinedRowsRawStr == "" && l.IP == ""
+}
+
+// touch marks the line as modified so it is rendered from its fields on save.
+// Repeated hosts are dropped at that point.
+func (l *Line) touch() {
+	if l.dup {
+		l.setHosts(l.hosts)
+		l.dup = false
+	}
+	l.verbatim = false
+	l.dirty = true
+	l.src = ""
+}
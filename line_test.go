@@ -0,0 +1,310 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// 测试Line的主机访问方法
+func TestLineHosts(t *testing.T) {
+	line := NewLine("127.0.0.1", "b.test", "a.test", "b.test")
+	if got := line.Hosts(); !reflect.DeepEqual(got, []string{"b.test", "a.test"}) {
+		t.Errorf("Hosts() = %v; want [b.test a.test]", got)
+	}
+
+	if line.AddHost("a.test") {
+		t.Errorf("AddHost(a.test) = true; want false for existing host")
+	}
+	if !line.AddHost("c.test") || line.NumHosts() != 3 {
+		t.Errorf("AddHost(c.test) failed, NumHosts() = %d", line.NumHosts())
+	}
+	if !line.RemoveHost("b.test") || line.HasHost("b.test") {
+		t.Errorf("RemoveHost(b.test) failed")
+	}
+	// 主机比较忽略ASCII字母的大小写
+	if !line.HasHost("A.Test") || line.AddHost("C.TEST") {
+		t.Errorf("HasHost(A.Test) or AddHost(C.TEST) is case-sensitive")
+	}
+	if got := line.Hosts(); !reflect.DeepEqual(got, []string{"a.test", "c.test"}) {
+		t.Errorf("Hosts() = %v; want [a.test c.test]", got)
+	}
+
+	// 修改Hosts的返回值不应影响Line
+	line.Hosts()[0] = "changed"
+	if !line.HasHost("a.test") {
+		t.Errorf("Hosts() returned the internal slice")
+	}
+}
+
+// 测试主机较多的行使用查找表
+func TestLineHostsIndex(t *testing.T) {
+	line := NewLine("0.0.0.0")
+	for i := 0; i < indexThreshold*2; i++ {
+		line.AddHost(fmt.Sprintf("h%d.test", i))
+	}
+	if line.index == nil {
+		t.Fatalf("index not built for %d hosts", line.NumHosts())
+	}
+	line.RemoveHost("h3.test")
+	if line.HasHost("h3.test") || !line.HasHost("h4.test") {
+		t.Errorf("HasHost() inconsistent after RemoveHost")
+	}
+	if !line.HasHost("H5.Test") || !line.RemoveHost("H5.TEST") || line.HasHost("h5.test") {
+		t.Errorf("HasHost() or RemoveHost() is case-sensitive with index")
+	}
+	if line.NumHosts() != indexThreshold*2-2 {
+		t.Errorf("NumHosts() = %d; want %d", line.NumHosts(), indexThreshold*2-2)
+	}
+}
+
+// 测试保存时保持主机的原始顺序
+func TestSaveKeepsHostOrder(t *testing.T) {
+	filePath, err := createTestHostsFile("10.0.0.1 zeta.test alpha.test mid.test\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	err = hostsEdit.Edit("beta.test", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Edit(beta.test, 10.0.0.1) failed with error: %v", err)
+	}
+
+	contentBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read hosts file: %v", err)
+	}
+	want := "10.0.0.1 zeta.test alpha.test mid.test beta.test\n"
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+}
+
+// 测试已弃用的Host字段和访问方法保持同步
+func TestLineHostField(t *testing.T) {
+	filePath, err := createTestHostsFile("10.0.0.1 a.test b.test\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	line := hostsEdit.GetLineForHost("a.test")
+	want := map[string]struct{}{"a.test": {}, "b.test": {}}
+	if !reflect.DeepEqual(line.Host, want) {
+		t.Errorf("Host after load = %v; want %v", line.Host, want)
+	}
+	line.AddHost("c.test")
+	line.RemoveHost("b.test")
+	want = map[string]struct{}{"a.test": {}, "c.test": {}}
+	if !reflect.DeepEqual(line.Host, want) {
+		t.Errorf("Host after AddHost and RemoveHost = %v; want %v", line.Host, want)
+	}
+
+	// 直接修改Host字段，保存时生效
+	delete(line.Host, "a.test")
+	line.Host["z.test"] = struct{}{}
+	line.Host["d.test"] = struct{}{}
+	hostsEdit.Lines = append(hostsEdit.Lines, &Line{IP: "10.0.0.2", Host: map[string]struct{}{"x.test": {}}})
+	if err := hostsEdit.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	contentBytes, _ := os.ReadFile(filePath)
+	if want := "10.0.0.1 c.test d.test z.test\n10.0.0.2 x.test\n"; string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+	if hosts := line.Hosts(); !reflect.DeepEqual(hosts, []string{"c.test", "d.test", "z.test"}) {
+		t.Errorf("Hosts() after Save() = %v", hosts)
+	}
+}
+
+// 测试ParseLine函数
+func TestParseLine(t *testing.T) {
+	line, err := ParseLine("  10.0.0.1\tapi.test  web.test ")
+	if err != nil {
+		t.Fatalf("ParseLine() error = %v", err)
+	}
+	if line.IP != "10.0.0.1" || !reflect.DeepEqual(line.Hosts(), []string{"api.test", "web.test"}) {
+		t.Errorf("ParseLine() = %v %v; want 10.0.0.1 [api.test web.test]", line.IP, line.Hosts())
+	}
+
+	line, err = ParseLine("# 10.0.0.1 disabled.test")
+	if err != nil || !line.IsDisabled() {
+		t.Errorf("ParseLine(disabled entry) = %+v, %v", line, err)
+	}
+
+	line, err = ParseLine("# just a comment")
+	if err != nil || !line.IsComment || line.UndefinedRowsRawStr != "just a comment" {
+		t.Errorf("ParseLine(comment) = %+v, %v", line, err)
+	}
+
+	line, err = ParseLine("127.0.0.1 example.com  #  added by myapp")
+	if err != nil || !reflect.DeepEqual(line.Hosts(), []string{"example.com"}) || line.InlineComment != "added by myapp" {
+		t.Errorf("ParseLine(inline comment) = %v %q, %v; want [example.com] and the inline comment", line.Hosts(), line.InlineComment, err)
+	}
+	if got := FormatLine(line); got != "127.0.0.1 example.com # added by myapp" {
+		t.Errorf("FormatLine(inline comment) = %q", got)
+	}
+
+	// 不能作为主机名的尾部标记保存在Extra中
+	line, err = ParseLine("10.0.0.3 alias.test opt=1 other.test # note")
+	if err != nil || !reflect.DeepEqual(line.Hosts(), []string{"alias.test"}) || !reflect.DeepEqual(line.Extra, []string{"opt=1", "other.test"}) {
+		t.Errorf("ParseLine(extra tokens) = %v %v, %v; want [alias.test] [opt=1 other.test]", line.Hosts(), line.Extra, err)
+	}
+	if got := FormatLine(line); got != "10.0.0.3 alias.test opt=1 other.test # note" {
+		t.Errorf("FormatLine(extra tokens) = %q", got)
+	}
+
+	// 行尾的换行符被忽略
+	line, err = ParseLine("10.0.0.2 piped.test\r\n")
+	if err != nil || line.IP != "10.0.0.2" || FormatLine(line) != "10.0.0.2 piped.test" {
+		t.Errorf("ParseLine(with line ending) = %+v, %v", line, err)
+	}
+
+	line, err = ParseLine(" \t")
+	if err != nil || !line.isBlank() {
+		t.Errorf("ParseLine(blank) = %+v, %v; want blank line", line, err)
+	}
+
+	line, err = ParseLine("999.0.0.1 bad.test")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || !errors.Is(err, ErrMalformedLine) {
+		t.Errorf("ParseLine(invalid ip) error = %v; want ErrMalformedLine", err)
+	}
+	if line == nil || line.UndefinedRowsRawStr != "999.0.0.1 bad.test" {
+		t.Errorf("ParseLine(invalid ip) = %+v; want line with UndefinedRowsRawStr", line)
+	}
+}
+
+// 测试严格模式的错误包含行号
+func TestNewParseError(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n# comment\n10.0.0.1 api.test localhost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	_, err = New(filePath, true)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("New() error = %v; want *ParseError", err)
+	}
+	if parseErr.Line != 3 || !errors.Is(err, ErrDuplicateHost) {
+		t.Errorf("New() error = %v; want duplicate host on line 3", err)
+	}
+}
+
+// 测试ParseError的列位置
+func TestParseErrorColumn(t *testing.T) {
+	tests := []struct {
+		content string
+		opts    []Option
+		line    int
+		column  int
+		length  int
+	}{
+		{"127.0.0.1 a.test\n\t10.0.0.2  b.test A.test\n", nil, 2, 19, 6},
+		{"  999.0.0.1 bad.test\n", nil, 1, 3, 9},
+		{"10.0.0.1 ba!d.test\n", nil, 1, 12, 1},
+		{"10.0.0.1\n", nil, 1, 1, 8},
+		{"10.0.0.1 a.test opt=1 # note\n", nil, 1, 17, 5},
+		{"10.0.0.1 a.test bad_name.test\n", []Option{WithStrictHostnameValidation()}, 1, 17, 13},
+		// 列按字节计算
+		{"10.0.0.1 b\u00fccher.test\u3000 b\u00fccher.test\n", nil, 1, 26, 12},
+	}
+	for _, tt := range tests {
+		filePath, err := createTestHostsFile(tt.content)
+		if err != nil {
+			t.Fatalf("Failed to create test hosts file: %v", err)
+		}
+		_, err = New(filePath, true, tt.opts...)
+		os.Remove(filePath)
+
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("New(%q) error = %v; want *ParseError", tt.content, err)
+			continue
+		}
+		if parseErr.Line != tt.line || parseErr.Column != tt.column || parseErr.Length != tt.length {
+			t.Errorf("New(%q) error at %d:%d+%d; want %d:%d+%d", tt.content,
+				parseErr.Line, parseErr.Column, parseErr.Length, tt.line, tt.column, tt.length)
+		}
+	}
+
+	_, err := ParseLine("\t999.0.0.1 bad.test")
+	want := `malformed line: "999.0.0.1 bad.test"`
+	if err == nil || err.Error() != want {
+		t.Errorf("ParseLine() error = %v; want %s", err, want)
+	}
+	err = &ParseError{Line: 2, Column: 3, Length: 1, Text: "x", Err: ErrMalformedLine}
+	if want := `line 2, column 3: malformed line: "x"`; err.Error() != want {
+		t.Errorf("Error() = %q; want %q", err.Error(), want)
+	}
+}
+
+// 测试FormatLine函数
+func TestFormatLine(t *testing.T) {
+	tests := []struct {
+		line *Line
+		opts []RenderOption
+		want string
+	}{
+		{NewLine("10.0.0.1", "b.test", "a.test"), nil, "10.0.0.1 b.test a.test"},
+		{NewLine("10.0.0.1", "b.test", "a.test"), []RenderOption{WithSeparator("\t")}, "10.0.0.1\tb.test\ta.test"},
+		{&Line{IsComment: true, UndefinedRowsRawStr: "comment"}, nil, "# comment"},
+		{&Line{IsComment: true, IP: "10.0.0.1", hosts: []string{"x.test"}}, nil, "# 10.0.0.1 x.test"},
+		{&Line{UndefinedRowsRawStr: "garbage here"}, nil, "garbage here"},
+		{NewLine("::1", "a.test", "b.test"), []RenderOption{WithIPColumnWidth(6)}, "::1   a.test b.test"},
+		{NewLine("10.0.0.1", "a.test"), []RenderOption{WithIPColumnWidth(4)}, "10.0.0.1 a.test"},
+		{NewLine("::1", "a.test"), []RenderOption{WithSeparator("\t"), WithIPColumnWidth(16)}, "::1\t\ta.test"},
+		{&Line{}, nil, ""},
+	}
+	for _, tt := range tests {
+		if got := FormatLine(tt.line, tt.opts...); got != tt.want {
+			t.Errorf("FormatLine() = %q; want %q", got, tt.want)
+		}
+	}
+}
+
+// 测试保存时使用WithRenderOptions
+func TestSaveWithSeparator(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false, WithRenderOptions(WithSeparator("\t")))
+	err = hostsEdit.Edit("myapp.local", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Edit(myapp.local, 127.0.0.1) failed with error: %v", err)
+	}
+
+	contentBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read hosts file: %v", err)
+	}
+	if string(contentBytes) != "127.0.0.1\tlocalhost\tmyapp.local\n" {
+		t.Errorf("saved content = %q", contentBytes)
+	}
+}
@@ -0,0 +1,72 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"log/slog"
+	"time"
+)
+
+// WithLogger makes the instance log every write of the hosts file to logger
+// at debug level, before and after the write, with the attributes file,
+// operation, host and ip, and after it also duration, strategy and error.
+// host and ip are only present for operations on a single host.
+func WithLogger(logger *slog.Logger) Option {
+	return func(h *HostsEdit) {
+		h.logger = logger
+	}
+}
+
+// operation describes the call that triggered a save, for logging.
+type operation struct {
+	name string
+	host string
+	ip   string
+}
+
+// logWrite logs the start of a write for op and returns the function that
+// logs its end. Both do nothing without a logger.
+func (h *HostsEdit) logWrite(op operation) func(strategy WriteStrategy, err error) {
+	return startWriteLog(h.logger, h.FilePath, op)
+}
+
+// startWriteLog is logWrite for a write of file that does not happen on the
+// goroutine using the instance.
+func startWriteLog(logger *slog.Logger, file string, op operation) func(strategy WriteStrategy, err error) {
+	if logger == nil {
+		return func(WriteStrategy, error) {}
+	}
+
+	attrs := []any{"file", file, "operation", op.name}
+	if op.host != "" {
+		attrs = append(attrs, "host", op.host)
+	}
+	if op.ip != "" {
+		attrs = append(attrs, "ip", op.ip)
+	}
+	logger.Debug("writing hosts file", attrs...)
+
+	start := time.Now()
+	return func(strategy WriteStrategy, err error) {
+		attrs := append(attrs, "duration", time.Since(start))
+		if err != nil {
+			logger.Debug("writing hosts file failed", append(attrs, "error", err)...)
+			return
+		}
+		logger.Debug("wrote hosts file", append(attrs, "strategy", strategy.String())...)
+	}
+}
@@ -0,0 +1,70 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+// 测试WithLogger记录写入日志
+func TestWithLogger(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	hostsEdit, _ := New(filePath, false, WithLogger(logger))
+
+	err = hostsEdit.Edit("myapp.local", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Edit(myapp.local, 10.0.0.1) failed with error: %v", err)
+	}
+
+	records := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(records) != 2 {
+		t.Fatalf("logged %d records; want 2: %s", len(records), buf.String())
+	}
+	for i, want := range []string{"writing hosts file", "wrote hosts file"} {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(records[i]), &record); err != nil {
+			t.Fatalf("invalid log record %q: %v", records[i], err)
+		}
+		if record["msg"] != want || record["level"] != "DEBUG" || record["file"] != filePath ||
+			record["operation"] != "Edit" || record["host"] != "myapp.local" || record["ip"] != "10.0.0.1" {
+			t.Errorf("log record %d = %v; want %q for Edit myapp.local", i, record, want)
+		}
+		if i == 1 && (record["duration"] == nil || record["strategy"] != "atomic") {
+			t.Errorf("log record %d = %v; want duration and strategy", i, record)
+		}
+	}
+
+	// 没有设置日志时不记录
+	buf.Reset()
+	hostsEdit, _ = New(filePath, false)
+	hostsEdit.Delete("myapp.local")
+	if buf.Len() != 0 {
+		t.Errorf("logged %q without WithLogger", buf.String())
+	}
+}
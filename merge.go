@@ -0,0 +1,88 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+// MergeWith adds the active entries of other to h and saves the file once,
+// only if something was added. Hosts that h already maps are kept as they
+// are, whatever IP address other gives them, and hosts repeated in other
+// are added once. Each entry line of other that still has hosts left is
+// added as a new line, and the new lines are kept together in the order of
+// other: at the end of the file with InsertAppend, at the top otherwise.
+// Comments of other are not merged.
+func (h *HostsEdit) MergeWith(other *HostsEdit) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	if !h.merge(other) {
+		return nil
+	}
+	return h.saveToFile(operation{name: "MergeWith"})
+}
+
+// MergeFromURL downloads a hosts file as NewFromURL does and merges it into
+// h as MergeWith does. The timeout set by WithHTTPTimeout for h is used.
+func (h *HostsEdit) MergeFromURL(rawURL string) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	other, err := NewFromURL(rawURL, WithHTTPTimeout(h.httpTimeout))
+	if err != nil {
+		return err
+	}
+	if !h.merge(other) {
+		return nil
+	}
+	return h.saveToFile(operation{name: "MergeFromURL"})
+}
+
+// merge adds the entries of other in memory and reports whether anything
+// was added.
+func (h *HostsEdit) merge(other *HostsEdit) bool {
+	mapped, _ := h.effective()
+	var newLines []*Line
+	for _, line := range other.Lines {
+		if !isActive(line) {
+			continue
+		}
+		var hosts []string
+		for _, host := range line.hosts {
+			if h.lowercaseHosts {
+				host = lowerASCII(host)
+			}
+			if _, ok := mapped[lowerASCII(host)]; !ok {
+				mapped[lowerASCII(host)] = line.IP
+				hosts = append(hosts, host)
+			}
+		}
+		if len(hosts) > 0 {
+			newLine := NewLine(line.IP, hosts...)
+			newLine.indent = h.indent
+			newLines = append(newLines, newLine)
+		}
+	}
+	if len(newLines) == 0 {
+		return false
+	}
+
+	if h.insertPolicy == InsertAppend {
+		h.Lines = append(h.Lines, newLines...)
+	} else {
+		i := h.topIndex()
+		h.Lines = append(h.Lines[:i], append(newLines, h.Lines[i:]...)...)
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// 测试MergeWith方法
+func TestMergeWith(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n10.0.0.1 api.test\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false, WithInsertPolicy(InsertAppend))
+	other, _ := NewFromReader(strings.NewReader("# remote\n10.0.0.9 api.test web.test\n0.0.0.0 ads.test\n0.0.0.0 ads.test tracker.test\n"), false)
+
+	err = hostsEdit.MergeWith(other)
+	if err != nil {
+		t.Fatalf("MergeWith() error = %v", err)
+	}
+	want := "127.0.0.1 localhost\n10.0.0.1 api.test\n10.0.0.9 web.test\n0.0.0.0 ads.test\n0.0.0.0 tracker.test\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("MergeWith() wrote %q; want %q", contentBytes, want)
+	}
+
+	// 再次合并不做任何修改
+	before := len(hostsEdit.Lines)
+	if err := hostsEdit.MergeWith(other); err != nil || len(hostsEdit.Lines) != before {
+		t.Errorf("second MergeWith() = %v with %d lines; want no change", err, len(hostsEdit.Lines))
+	}
+}
+
+// 测试MergeFromURL方法
+func TestMergeFromURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("0.0.0.0 ads.test\n127.0.0.1 localhost\n"))
+	}))
+	defer server.Close()
+
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	err = hostsEdit.MergeFromURL(server.URL)
+	if err != nil {
+		t.Fatalf("MergeFromURL() error = %v", err)
+	}
+	want := "0.0.0.0 ads.test\n127.0.0.1 localhost\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("MergeFromURL() wrote %q; want %q", contentBytes, want)
+	}
+}
+
+// 测试合并时大小写不同的主机视为已存在
+func TestMergeWithMixedCase(t *testing.T) {
+	filePath, err := createTestHostsFile("10.0.0.1 Api.test\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	other, _ := NewFromReader(strings.NewReader("10.0.0.2 api.test\n10.0.0.3 new.test\n"), false)
+	if err := hostsEdit.MergeWith(other); err != nil {
+		t.Fatalf("MergeWith() error = %v", err)
+	}
+	contentBytes, _ := os.ReadFile(filePath)
+	if want := "10.0.0.3 new.test\n10.0.0.1 Api.test\n"; string(contentBytes) != want {
+		t.Errorf("MergeWith() wrote %q; want %q", contentBytes, want)
+	}
+}
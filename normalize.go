@@ -0,0 +1,180 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// IPNormalize returns ip in its canonical form: IPv6 addresses are written
+// in lowercase with the longest run of zero groups shortened to "::", so
+// 0000:0000::0001 becomes ::1. IPv4-mapped IPv6 addresses keep their IPv6
+// form, ::FFFF:127.0.0.1 becomes ::ffff:127.0.0.1. A string that is not a
+// valid IP address is returned unchanged.
+func IPNormalize(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ip
+	}
+	return addr.String()
+}
+
+// sameIP reports whether a and b are the same IP address, that is they
+// have the same IPNormalize form, such as ::1 and 0:0:0:0:0:0:0:1. Strings
+// that are not valid addresses are only equal to themselves.
+func sameIP(a, b string) bool {
+	if a == b {
+		return true
+	}
+	x, err := netip.ParseAddr(a)
+	if err != nil {
+		return false
+	}
+	y, err := netip.ParseAddr(b)
+	return err == nil && x == y
+}
+
+// NormalizeIPs rewrites the IP address of every entry, including disabled
+// ones, with IPNormalize. The file is saved only if an address changed.
+func (h *HostsEdit) NormalizeIPs() error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	if !normalizeIPs(h.Lines) {
+		return nil
+	}
+	return h.saveToFile(operation{name: "NormalizeIPs"})
+}
+
+// normalizeIPs applies IPNormalize to lines and reports whether anything
+// changed.
+func normalizeIPs(lines []*Line) bool {
+	changed := false
+	for _, line := range lines {
+		if line.UndefinedRowsRawStr != "" || line.IP == "" {
+			continue
+		}
+		if ip := IPNormalize(line.IP); ip != line.IP {
+			line.setIP(ip)
+			line.touch()
+			changed = true
+		}
+	}
+	return changed
+}
+
+// HostNormalize returns host in lowercase and without a trailing dot, so
+// Example.COM. becomes example.com. Host names are case-insensitive
+// (RFC 1034, section 3.1). Only ASCII letters are folded; other characters,
+// such as those of internationalized labels, are left as they are.
+func HostNormalize(host string) string {
+	return strings.TrimSuffix(lowerASCII(host), ".")
+}
+
+// lowerASCII returns s with ASCII letters in lowercase.
+func lowerASCII(s string) string {
+	for i := 0; i < len(s); i++ {
+		if 'A' <= s[i] && s[i] <= 'Z' {
+			b := []byte(s)
+			for j := i; j < len(b); j++ {
+				if 'A' <= b[j] && b[j] <= 'Z' {
+					b[j] += 'a' - 'A'
+				}
+			}
+			return string(b)
+		}
+	}
+	return s
+}
+
+// equalFoldASCII reports whether a and b are equal, ignoring the case of
+// ASCII letters.
+func equalFoldASCII(a, b string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := 0; i < len(a); i++ {
+		x, y := a[i], b[i]
+		if 'A' <= x && x <= 'Z' {
+			x += 'a' - 'A'
+		}
+		if 'A' <= y && y <= 'Z' {
+			y += 'a' - 'A'
+		}
+		if x != y {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeHosts rewrites the hosts of every entry, including disabled
+// ones, with HostNormalize. Hosts that are repeated on the same line, also
+// after normalization, are merged. The file is saved only if a host changed.
+func (h *HostsEdit) NormalizeHosts() error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	if !normalizeHosts(h.Lines) {
+		return nil
+	}
+	return h.saveToFile(operation{name: "NormalizeHosts"})
+}
+
+// normalizeHosts applies HostNormalize to lines and reports whether anything
+// changed.
+func normalizeHosts(lines []*Line) bool {
+	changed := false
+	for _, line := range lines {
+		if line.UndefinedRowsRawStr != "" || line.IP == "" {
+			continue
+		}
+		hosts := make([]string, len(line.hosts))
+		lineChanged := false
+		for i, host := range line.hosts {
+			hosts[i] = HostNormalize(host)
+			lineChanged = lineChanged || hosts[i] != host
+		}
+		if lineChanged || line.dup {
+			line.setHosts(hosts)
+			line.touch()
+			changed = true
+		}
+	}
+	return changed
+}
+
+// lowercaseHosts folds the ASCII letters of the hosts of lines to lowercase,
+// for WithLowercaseHosts.
+func lowercaseHosts(lines []*Line) {
+	for _, line := range lines {
+		if line.UndefinedRowsRawStr != "" || line.IP == "" {
+			continue
+		}
+		hosts := make([]string, len(line.hosts))
+		changed := false
+		for i, host := range line.hosts {
+			hosts[i] = lowerASCII(host)
+			changed = changed || hosts[i] != host
+		}
+		if changed {
+			line.setHosts(hosts)
+			line.touch()
+		}
+	}
+}
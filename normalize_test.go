@@ -0,0 +1,218 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// 测试IPNormalize函数
+func TestIPNormalize(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{"127.0.0.1", "127.0.0.1"},
+		{"0000:0000::0001", "::1"},
+		{"::FFFF:127.0.0.1", "::ffff:127.0.0.1"},
+		{"FE80:0:0:0:0:0:0:1", "fe80::1"},
+		{"2001:DB8:0:0:1:0:0:1", "2001:db8::1:0:0:1"},
+		{"not an ip", "not an ip"},
+	}
+	for _, tt := range tests {
+		if got := IPNormalize(tt.ip); got != tt.want {
+			t.Errorf("IPNormalize(%q) = %q; want %q", tt.ip, got, tt.want)
+		}
+	}
+}
+
+// 测试写法不同但相等的IP地址被视为同一地址
+func TestEqualIPAddresses(t *testing.T) {
+	hostsContent := "::1 a.test\n0:0:0:0:0:0:0:2 b.test\nfe80::abcd c.test\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	if !hostsEdit.Contains("c.test", "FE80:0:0:0:0:0:0:ABCD") || hostsEdit.GetLineForIP("0000::0001") == nil {
+		t.Errorf("Contains() or GetLineForIP() compares addresses as text")
+	}
+
+	// 相同的地址不修改文件，新主机加入已有的行，保留原来的写法
+	err = hostsEdit.Edit("a.test", "0:0:0:0:0:0:0:1")
+	if err != nil {
+		t.Fatalf("Edit(a.test, 0:0:0:0:0:0:0:1) failed with error: %v", err)
+	}
+	err = hostsEdit.Edit("d.test", "0::2")
+	if err != nil {
+		t.Fatalf("Edit(d.test, 0::2) failed with error: %v", err)
+	}
+	n, err := hostsEdit.ReplaceIP("FE80::ABCD", "fe80::1")
+	if n != 1 || err != nil {
+		t.Fatalf("ReplaceIP(FE80::ABCD, fe80::1) = %d, %v; want 1, nil", n, err)
+	}
+
+	want := "::1 a.test\n0:0:0:0:0:0:0:2 b.test d.test\nfe80::1 c.test\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+}
+
+// 测试NormalizeIPs方法
+func TestNormalizeIPs(t *testing.T) {
+	hostsContent := "127.0.0.1 localhost\n0000:0000::0001 ipv6host\n# ::FFFF:127.0.0.1 mapped\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	err = hostsEdit.NormalizeIPs()
+	if err != nil {
+		t.Fatalf("NormalizeIPs() error = %v", err)
+	}
+
+	want := "127.0.0.1 localhost\n::1 ipv6host\n# ::ffff:127.0.0.1 mapped\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("NormalizeIPs() wrote %q; want %q", contentBytes, want)
+	}
+}
+
+// 测试HostNormalize函数
+func TestHostNormalize(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"localhost", "localhost"},
+		{"Example.COM", "example.com"},
+		{"example.com.", "example.com"},
+		{"MyApp.Local.", "myapp.local"},
+		{"ÜBER.Test", "Über.test"},
+	}
+	for _, tt := range tests {
+		if got := HostNormalize(tt.host); got != tt.want {
+			t.Errorf("HostNormalize(%q) = %q; want %q", tt.host, got, tt.want)
+		}
+	}
+}
+
+// 测试NormalizeHosts方法
+func TestNormalizeHosts(t *testing.T) {
+	hostsContent := "127.0.0.1 localhost\n10.0.0.1 Example.COM example.com. www.example.com\n# 10.0.0.2 OFF.test\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	err = hostsEdit.NormalizeHosts()
+	if err != nil {
+		t.Fatalf("NormalizeHosts() error = %v", err)
+	}
+
+	want := "127.0.0.1 localhost\n10.0.0.1 example.com www.example.com\n# 10.0.0.2 off.test\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("NormalizeHosts() wrote %q; want %q", contentBytes, want)
+	}
+}
+
+// 测试WithAutoNormalize选项
+func TestWithAutoNormalize(t *testing.T) {
+	hostsContent := "0:0::1 LocalHost.\n10.0.0.1 a.test\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, err := New(filePath, false, WithAutoNormalize(), WithInsertPolicy(InsertAppend))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if ip, _ := hostsEdit.Get("localhost"); ip != "::1" {
+		t.Errorf("Get(localhost) = %q; want %q", ip, "::1")
+	}
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != hostsContent {
+		t.Errorf("New() with WithAutoNormalize changed the file to %q", contentBytes)
+	}
+
+	// 下一次保存写入规范化后的内容
+	err = hostsEdit.Edit("b.test", "10.0.0.2")
+	if err != nil {
+		t.Fatalf("Edit(b.test, 10.0.0.2) failed with error: %v", err)
+	}
+	want := "::1 localhost\n10.0.0.1 a.test\n10.0.0.2 b.test\n"
+	contentBytes, _ = os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+
+	// 严格模式在规范化之后检查重复
+	os.WriteFile(filePath, []byte("10.0.0.1 A.test\n10.0.0.2 a.test\n"), 0644)
+	if _, err := New(filePath, true, WithAutoNormalize()); !errors.Is(err, ErrDuplicateHost) {
+		t.Errorf("New() error = %v; want %v", err, ErrDuplicateHost)
+	}
+}
+
+// 测试WithLowercaseHosts选项
+func TestWithLowercaseHosts(t *testing.T) {
+	hostsContent := "10.0.0.1 Mixed.Test\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false, WithLowercaseHosts(), WithInsertPolicy(InsertAppend))
+	err = hostsEdit.Edit("New.TEST", "10.0.0.2")
+	if err != nil {
+		t.Fatalf("Edit(New.TEST, 10.0.0.2) failed with error: %v", err)
+	}
+	// 查找不区分大小写，已有的条目保持原样
+	err = hostsEdit.Edit("mixed.test", "10.0.0.3")
+	if err != nil {
+		t.Fatalf("Edit(mixed.test, 10.0.0.3) failed with error: %v", err)
+	}
+
+	want := "10.0.0.3 Mixed.Test\n10.0.0.2 new.test\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+
+	err = hostsEdit.ImportYAML(strings.NewReader("- ip: 10.0.0.4\n  hosts: [Imported.Test, ÄPFEL.Test]\n"))
+	if err != nil {
+		t.Fatalf("ImportYAML() error = %v", err)
+	}
+	want = "10.0.0.4 imported.test Äpfel.test\n"
+	contentBytes, _ = os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("ImportYAML() wrote %q; want %q", contentBytes, want)
+	}
+}
@@ -0,0 +1,199 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"errors"
+	"time"
+)
+
+// ChangeOp is the kind of change made to the mapping of a host.
+type ChangeOp int
+
+const (
+	// OpAdd means a host that was not mapped now is.
+	OpAdd ChangeOp = iota
+	// OpUpdate means a host is mapped to a different IP address.
+	OpUpdate
+	// OpDelete means a host is no longer mapped.
+	OpDelete
+)
+
+func (op ChangeOp) String() string {
+	switch op {
+	case OpAdd:
+		return "add"
+	case OpUpdate:
+		return "update"
+	case OpDelete:
+		return "delete"
+	}
+	return "unknown"
+}
+
+// OnChange registers fn to be called for every host whose effective mapping,
+// the first active entry for it, was changed by a save: Edit, Delete and
+// every other method that saves, including Save after lines were changed
+// directly. fn receives the new IP address for OpAdd and OpUpdate and the
+// old one for OpDelete. Changes are compared with the mapping saved last, or
+// the mapping when the first function was registered, and are reported
+// once the save has succeeded. Functions are called in the order they were
+// registered.
+func (h *HostsEdit) OnChange(fn func(op ChangeOp, host, ip string)) {
+	h.subMu.Lock()
+	if !h.hasListenersLocked() {
+		h.notified, h.notifiedOrder = h.effective()
+	}
+	h.subMu.Unlock()
+	h.onChange = append(h.onChange, fn)
+}
+
+// HostEvent is a change to the mapping of a host, sent to the channels
+// registered with Subscribe.
+type HostEvent struct {
+	Type      ChangeOp
+	Host      string
+	IP        string // new IP address, "" for OpDelete
+	OldIP     string // previous IP address, "" for OpAdd
+	Timestamp time.Time
+}
+
+// Subscribe registers ch to receive a HostEvent for every change that
+// OnChange reports, once the save has succeeded. Events are sent without
+// blocking: if ch is not ready to receive, the event is dropped, so ch
+// should be buffered. It is an error to subscribe a nil channel or a
+// channel that is already subscribed.
+func (h *HostsEdit) Subscribe(ch chan<- HostEvent) error {
+	if ch == nil {
+		return errors.New("cannot subscribe a nil channel")
+	}
+	h.subMu.Lock()
+	defer h.subMu.Unlock()
+	for _, c := range h.subscribers {
+		if c == ch {
+			return errors.New("channel is already subscribed")
+		}
+	}
+	if !h.hasListenersLocked() {
+		h.notified, h.notifiedOrder = h.effective()
+	}
+	h.subscribers = append(h.subscribers, ch)
+	return nil
+}
+
+// Unsubscribe removes ch from the channels registered with Subscribe. It
+// does nothing if ch is not subscribed. The channel is not closed.
+// Unsubscribe may be called from another goroutine, such as the one
+// receiving from ch.
+func (h *HostsEdit) Unsubscribe(ch chan<- HostEvent) {
+	h.subMu.Lock()
+	defer h.subMu.Unlock()
+	for i, c := range h.subscribers {
+		if c == ch {
+			h.subscribers = append(h.subscribers[:i:i], h.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+// hasListeners reports whether an OnChange function or a channel is
+// registered.
+func (h *HostsEdit) hasListeners() bool {
+	h.subMu.Lock()
+	defer h.subMu.Unlock()
+	return h.hasListenersLocked()
+}
+
+func (h *HostsEdit) hasListenersLocked() bool {
+	return len(h.onChange) > 0 || len(h.subscribers) > 0 || len(h.watchers) > 0
+}
+
+// notifyChange calls the OnChange functions and sends the events of
+// Subscribe and Watch for the changes since the last call. It is called
+// after every successful save.
+func (h *HostsEdit) notifyChange() {
+	h.changeNotifier()()
+}
+
+// changeNotifier returns the function that notifies the changes of the
+// current lines once a save of them has succeeded. Everything it needs from
+// the lines is computed here, so it may be called on another goroutine
+// while h is used.
+func (h *HostsEdit) changeNotifier() func() {
+	if !h.hasListeners() {
+		return func() {}
+	}
+	onChange, clock := h.onChange, h.clock
+	ips, order := h.effective()
+	lines := h.lineIndex()
+
+	return func() {
+		h.subMu.Lock()
+		subscribers := append([]chan<- HostEvent(nil), h.subscribers...)
+		watchers := append([]*watcher(nil), h.watchers...)
+		d := diffEffective(h.notified, h.notifiedOrder, ips, order)
+		h.notified, h.notifiedOrder = ips, order
+		h.subMu.Unlock()
+		sendChanges(onChange, subscribers, watchers, d, lines, clock())
+	}
+}
+
+// sendChanges calls the OnChange functions and sends the events of
+// Subscribe and Watch for d. lines is the result of lineIndex, and now the
+// timestamp of the events of Subscribe.
+func sendChanges(onChange []func(op ChangeOp, host, ip string), subscribers []chan<- HostEvent, watchers []*watcher, d Diff, lines map[string]int, now time.Time) {
+	for _, fn := range onChange {
+		for _, e := range d.Removed {
+			fn(OpDelete, e.Host, e.IP)
+		}
+		for _, e := range d.Added {
+			fn(OpAdd, e.Host, e.IP)
+		}
+		for _, c := range d.Changed {
+			fn(OpUpdate, c.Host, c.NewIP)
+		}
+	}
+	if len(subscribers) > 0 {
+		sendHostEvents(subscribers, d, now)
+	}
+	if len(watchers) > 0 {
+		sendChangeEvents(watchers, d, lines)
+	}
+}
+
+// sendHostEvents sends the events of d to the channels of Subscribe.
+func sendHostEvents(subscribers []chan<- HostEvent, d Diff, now time.Time) {
+	var events []HostEvent
+	for _, e := range d.Removed {
+		events = append(events, HostEvent{Type: OpDelete, Host: e.Host, OldIP: e.IP, Timestamp: now})
+	}
+	for _, e := range d.Added {
+		events = append(events, HostEvent{Type: OpAdd, Host: e.Host, IP: e.IP, Timestamp: now})
+	}
+	for _, c := range d.Changed {
+		events = append(events, HostEvent{Type: OpUpdate, Host: c.Host, IP: c.NewIP, OldIP: c.OldIP, Timestamp: now})
+	}
+	for _, ch := range subscribers {
+		for _, e := range events {
+			// 不阻塞保存，接收方没有准备好时丢弃事件
+			select {
+			case ch <- e:
+			default:
+			}
+		}
+	}
+}
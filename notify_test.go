@@ -0,0 +1,159 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// 测试OnChange回调
+func TestOnChange(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n10.0.0.1 a.test b.test\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	var events []string
+	hostsEdit.OnChange(func(op ChangeOp, host, ip string) {
+		events = append(events, fmt.Sprintf("%v %s %s", op, host, ip))
+	})
+	calls := 0
+	hostsEdit.OnChange(func(op ChangeOp, host, ip string) {
+		calls++
+	})
+
+	hostsEdit.Edit("new.test", "10.0.0.2")
+	hostsEdit.Edit("a.test", "10.0.0.3")
+	hostsEdit.Delete("b.test")
+	hostsEdit.ReplaceIP("10.0.0.2", "10.0.0.4")
+
+	// 保存失败时不通知，成功保存后再通知
+	errVeto := errors.New("veto")
+	veto := true
+	hostsEdit.BeforeSave(func(lines []*Line) error {
+		if veto {
+			return errVeto
+		}
+		return nil
+	})
+	if err := hostsEdit.Delete("localhost"); !errors.Is(err, errVeto) {
+		t.Fatalf("Delete(localhost) error = %v; want %v", err, errVeto)
+	}
+	veto = false
+	if err := hostsEdit.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	want := []string{
+		"add new.test 10.0.0.2",
+		"update a.test 10.0.0.3",
+		"delete b.test 10.0.0.1",
+		"update new.test 10.0.0.4",
+		"delete localhost 127.0.0.1",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("OnChange events = %q; want %q", events, want)
+	}
+	if calls != len(want) {
+		t.Errorf("second OnChange function called %d times; want %d", calls, len(want))
+	}
+}
+
+// 测试Subscribe和Unsubscribe
+func TestSubscribe(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n10.0.0.1 a.test\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	hostsEdit, _ := New(filePath, false, WithClock(func() time.Time { return now }))
+	ch := make(chan HostEvent, 10)
+	full := make(chan HostEvent)
+	if err := hostsEdit.Subscribe(ch); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if err := hostsEdit.Subscribe(ch); err == nil {
+		t.Errorf("Subscribe() twice succeeded; want error")
+	}
+	if err := hostsEdit.Subscribe(nil); err == nil {
+		t.Errorf("Subscribe(nil) succeeded; want error")
+	}
+	// 没有接收方的通道不阻塞保存
+	hostsEdit.Subscribe(full)
+
+	hostsEdit.Edit("a.test", "10.0.0.2")
+	hostsEdit.Edit("b.test", "10.0.0.3")
+	hostsEdit.Delete("localhost")
+
+	want := []HostEvent{
+		{Type: OpUpdate, Host: "a.test", IP: "10.0.0.2", OldIP: "10.0.0.1", Timestamp: now},
+		{Type: OpAdd, Host: "b.test", IP: "10.0.0.3", Timestamp: now},
+		{Type: OpDelete, Host: "localhost", OldIP: "127.0.0.1", Timestamp: now},
+	}
+	for _, w := range want {
+		select {
+		case got := <-ch:
+			if got != w {
+				t.Errorf("event = %+v; want %+v", got, w)
+			}
+		default:
+			t.Fatalf("missing event %+v", w)
+		}
+	}
+
+	hostsEdit.Unsubscribe(ch)
+	hostsEdit.Delete("a.test")
+	if len(ch) != 0 {
+		t.Errorf("received %d events after Unsubscribe", len(ch))
+	}
+}
+
+// 测试大小写不同的重复主机不产生多余的事件
+func TestOnChangeMixedCase(t *testing.T) {
+	filePath, err := createTestHostsFile("10.0.0.1 Api.test\n10.0.0.2 api.test\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	var events []string
+	hostsEdit.OnChange(func(op ChangeOp, host, ip string) {
+		events = append(events, fmt.Sprintf("%v %s %s", op, host, ip))
+	})
+	watch, cancel := hostsEdit.Watch()
+	defer cancel()
+
+	if err := hostsEdit.Edit("api.test", "10.0.0.3"); err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+	if want := []string{"update Api.test 10.0.0.3"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("OnChange events = %q; want %q", events, want)
+	}
+	if len(watch) != 1 {
+		t.Errorf("Watch() received %d events; want 1", len(watch))
+	}
+}
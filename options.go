@@ -0,0 +1,164 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import "os"
+
+// Option configures a HostsEdit when it is created.
+type Option func(*HostsEdit)
+
+// WithPreserveFormatting keeps the original text of every line, including
+// blank lines, spacing, line endings and a missing final newline. Lines that
+// are not modified are written back byte for byte, so loading and saving a
+// file without changes produces identical content. Modified lines keep their
+// leading indentation.
+func WithPreserveFormatting() Option {
+	return func(h *HostsEdit) {
+		h.preserve = true
+	}
+}
+
+// WithInsertPolicy sets where Edit places the line for a new host.
+func WithInsertPolicy(p InsertPolicy) Option {
+	return func(h *HostsEdit) {
+		h.insertPolicy = p
+	}
+}
+
+// WithReuseDisabled makes Edit re-enable a commented-out entry for the host,
+// updating its IP address if needed, instead of adding a new line when the
+// host has no active entry.
+func WithReuseDisabled() Option {
+	return func(h *HostsEdit) {
+		h.reuseDisabled = true
+	}
+}
+
+// WithIndent sets the leading whitespace of the lines Edit adds, for files
+// whose entries are indented, for example under a section comment. By
+// default new lines are not indented.
+func WithIndent(indent string) Option {
+	return func(h *HostsEdit) {
+		h.indent = indent
+	}
+}
+
+// WithAutoNormalize applies IPNormalize and HostNormalize to every entry when
+// the file is loaded, before the checks of isParse. The file is not written
+// when it is loaded; the normalized entries are written by the next save.
+func WithAutoNormalize() Option {
+	return func(h *HostsEdit) {
+		h.autoNormalize = true
+	}
+}
+
+// WithStrictHostnameValidation makes loading the file fail with a
+// *ParseError wrapping ErrInvalidHostname for the first host of an active
+// entry that ValidateHostname rejects. It is independent of, and stricter
+// than, the isParse argument of New, which does not check host names.
+func WithStrictHostnameValidation() Option {
+	return func(h *HostsEdit) {
+		h.strictHostnames = true
+	}
+}
+
+// WithLowercaseHosts writes the hosts added by Edit, Import in any format,
+// ImportYAML and MergeWith in lowercase. Only ASCII letters are folded, so
+// internationalized labels are kept as they are. Entries already in the
+// file are not changed; NormalizeHosts or WithAutoNormalize rewrite them.
+func WithLowercaseHosts() Option {
+	return func(h *HostsEdit) {
+		h.lowercaseHosts = true
+	}
+}
+
+// WithCreateMode sets the permissions NewOrCreate gives a hosts file it
+// creates, before the umask. The default is 0644.
+func WithCreateMode(perm os.FileMode) Option {
+	return func(h *HostsEdit) {
+		h.createMode = perm
+	}
+}
+
+// WithReadOnly makes every method that changes the hosts file, such as Edit,
+// Delete and Save, return ErrReadOnly without changing anything, for
+// processes that only inspect the file. Lines changed directly through the
+// Lines field cannot be saved either.
+func WithReadOnly() Option {
+	return func(h *HostsEdit) {
+		h.readOnly = true
+	}
+}
+
+// WithMaxHostsPerLine splits entry lines with more than n hosts into lines
+// of at most n hosts with the same IP address when the file is saved, for
+// resolvers with a limit on the length of a line. The hosts keep their order
+// and an inline comment stays on the first line. The split lines are also
+// the lines of the instance after the save. By default lines are not split.
+func WithMaxHostsPerLine(n int) Option {
+	return func(h *HostsEdit) {
+		h.maxHostsPerLine = n
+	}
+}
+
+// WithSync sets whether every write of the hosts file is synced to disk
+// before the saving method returns, so that a power loss right after it
+// cannot leave the file empty or truncated. When the file is replaced, the
+// directory is synced as well, so that the rename of the new file persists.
+// Syncing is on by default for the hosts file of the operating system, at
+// the path of DefaultPath without environment variables or the Windows file
+// of WithWindowsHostsFromWSL, and off for other files.
+//
+// A sync waits for the storage device. BenchmarkSaveSync measures it: on a
+// virtualized ext4 disk it adds about 0.06ms to a save of a small file, but
+// on SD cards and other flash storage of embedded devices a sync commonly
+// takes several to tens of milliseconds.
+func WithSync(sync bool) Option {
+	return func(h *HostsEdit) {
+		h.fsync, h.fsyncSet = sync, true
+	}
+}
+
+// Encoding is the character encoding used to save the hosts file.
+type Encoding int
+
+const (
+	// EncodingUTF8 saves the file as UTF-8 without a byte order mark. This
+	// is the default, except that WithPreserveFormatting keeps the byte order
+	// mark of a file that has one.
+	EncodingUTF8 Encoding = iota
+	// EncodingUTF8BOM saves the file as UTF-8 with a byte order mark, for
+	// Windows tools that require it.
+	EncodingUTF8BOM
+)
+
+// WithEncoding sets the character encoding used to save the hosts file.
+func WithEncoding(enc Encoding) Option {
+	return func(h *HostsEdit) {
+		h.bom = enc == EncodingUTF8BOM
+		h.encodingSet = true
+	}
+}
+
+// WithRenderOptions sets how entry lines are rendered when the file is saved.
+func WithRenderOptions(opts ...RenderOption) Option {
+	return func(h *HostsEdit) {
+		for _, opt := range opts {
+			opt(&h.renderConfig)
+		}
+	}
+}
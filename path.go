@@ -0,0 +1,75 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// pathEnvVars are the environment variables that override the platform hosts
+// file path, in order of precedence.
+var pathEnvVars = []string{"HOSTS_FILE", "HOSTALIASES"}
+
+// DefaultPath returns the path of the hosts file to edit. The first of the
+// HOSTS_FILE and HOSTALIASES environment variables that is set to a
+// non-blank value wins, and its name is returned as envVar; otherwise the
+// platform path is returned, such as /etc/hosts, with an empty envVar.
+func DefaultPath() (path, envVar string) {
+	for _, name := range pathEnvVars {
+		if v := strings.TrimSpace(os.Getenv(name)); v != "" {
+			return v, name
+		}
+	}
+	return platformPath(), ""
+}
+
+// platformPath returns the location of the hosts file of the operating system.
+func platformPath() string {
+	if runtime.GOOS == "windows" {
+		root := os.Getenv("SystemRoot")
+		if root == "" {
+			root = `C:\Windows`
+		}
+		return filepath.Join(root, "System32", "drivers", "etc", "hosts")
+	}
+	return "/etc/hosts"
+}
+
+// NewDefault is like New for the file returned by DefaultPath. With
+// WithWindowsHostsFromWSL, the Windows hosts file is used instead when
+// running in WSL and no environment variable overrides the path.
+func NewDefault(isParse bool, opts ...Option) (*HostsEdit, error) {
+	path, envVar := DefaultPath()
+
+	probe := &HostsEdit{}
+	for _, opt := range opts {
+		opt(probe)
+	}
+	if probe.wsl && envVar == "" {
+		if wslPath, err := WindowsHostsPathFromWSL(); err == nil {
+			path = wslPath
+		} else if !errors.Is(err, ErrNotWSL) {
+			return nil, err
+		}
+	}
+
+	return New(path, isParse, opts...)
+}
@@ -0,0 +1,64 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"os"
+	"testing"
+)
+
+// 测试DefaultPath读取环境变量
+func TestDefaultPath(t *testing.T) {
+	tests := []struct {
+		hostsFile   string
+		hostAliases string
+		path        string
+		envVar      string
+	}{
+		{"/tmp/test-hosts", "/tmp/aliases", "/tmp/test-hosts", "HOSTS_FILE"},
+		{"", "/tmp/aliases", "/tmp/aliases", "HOSTALIASES"},
+		{"  ", " \t", platformPath(), ""},
+		{"", "", platformPath(), ""},
+	}
+	for _, tt := range tests {
+		t.Setenv("HOSTS_FILE", tt.hostsFile)
+		t.Setenv("HOSTALIASES", tt.hostAliases)
+		path, envVar := DefaultPath()
+		if path != tt.path || envVar != tt.envVar {
+			t.Errorf("DefaultPath() with HOSTS_FILE=%q HOSTALIASES=%q = %q, %q; want %q, %q",
+				tt.hostsFile, tt.hostAliases, path, envVar, tt.path, tt.envVar)
+		}
+	}
+}
+
+// 测试NewDefault使用环境变量指定的文件
+func TestNewDefault(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	t.Setenv("HOSTS_FILE", filePath)
+	hostsEdit, err := NewDefault(false)
+	if err != nil {
+		t.Fatalf("NewDefault() error = %v", err)
+	}
+	if hostsEdit.FilePath != filePath || !hostsEdit.Exists("localhost") {
+		t.Errorf("NewDefault() loaded %q; want %q", hostsEdit.FilePath, filePath)
+	}
+}
@@ -0,0 +1,102 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"bytes"
+	"net"
+	"sort"
+)
+
+// PrettyOptions configures Pretty.
+type PrettyOptions struct {
+	// IPWidth is the column at which the hosts of every entry start. If it
+	// is 0, the length of the longest IP address, including the "# " in
+	// front of disabled entries, plus one is used.
+	IPWidth int
+	// Tabs separates the columns with tabs instead of spaces.
+	Tabs bool
+	// Sort orders the entry lines by IP address, IPv4 before IPv6, and then
+	// by their first host. Comments and other lines keep their positions, and
+	// lines with the same address keep their relative order.
+	Sort bool
+}
+
+// Pretty aligns the hosts of all entry lines, including disabled ones, in a
+// column and saves the file. The alignment is kept for the following saves
+// of this instance.
+func (h *HostsEdit) Pretty(opts PrettyOptions) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	var entries []int
+	width := opts.IPWidth
+	for i, line := range h.Lines {
+		if line.UndefinedRowsRawStr != "" || line.IP == "" {
+			continue
+		}
+		entries = append(entries, i)
+		n := len(line.IP) + 1
+		if line.IsComment {
+			n += len("# ")
+		}
+		if opts.IPWidth == 0 && n > width {
+			width = n
+		}
+		line.touch()
+	}
+
+	if opts.Sort {
+		sorted := make([]*Line, len(entries))
+		for i, idx := range entries {
+			sorted[i] = h.Lines[idx]
+		}
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return lessLine(sorted[i], sorted[j])
+		})
+		for i, idx := range entries {
+			h.Lines[idx] = sorted[i]
+		}
+	}
+
+	h.renderConfig.ipWidth = width
+	h.renderConfig.separator = " "
+	if opts.Tabs {
+		h.renderConfig.separator = "\t"
+	}
+	return h.saveToFile(operation{name: "Pretty"})
+}
+
+// lessLine orders entry lines by IP address and then by first host.
+func lessLine(a, b *Line) bool {
+	ipA, ipB := net.ParseIP(a.IP), net.ParseIP(b.IP)
+	v4A, v4B := ipA.To4() != nil, ipB.To4() != nil
+	if v4A != v4B {
+		return v4A
+	}
+	if c := bytes.Compare(ipA.To16(), ipB.To16()); c != 0 {
+		return c < 0
+	}
+	var hostA, hostB string
+	if len(a.hosts) > 0 {
+		hostA = a.hosts[0]
+	}
+	if len(b.hosts) > 0 {
+		hostB = b.hosts[0]
+	}
+	return hostA < hostB
+}
@@ -0,0 +1,61 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"os"
+	"testing"
+)
+
+// 测试Pretty方法
+func TestPretty(t *testing.T) {
+	hostsContent := "# hosts\n::1 ip6-localhost\n10.0.0.1 api.test\n127.0.0.1 localhost myapp.local\n# 192.168.1.1 router\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	tests := []struct {
+		name string
+		opts PrettyOptions
+		want string
+	}{
+		{
+			name: "spaces",
+			opts: PrettyOptions{},
+			want: "# hosts\n::1           ip6-localhost\n10.0.0.1      api.test\n127.0.0.1     localhost myapp.local\n# 192.168.1.1 router\n",
+		},
+		{
+			name: "sorted tabs",
+			opts: PrettyOptions{IPWidth: 16, Tabs: true, Sort: true},
+			want: "# hosts\n10.0.0.1\tapi.test\n127.0.0.1\tlocalhost\tmyapp.local\n# 192.168.1.1\trouter\n::1\t\tip6-localhost\n",
+		},
+	}
+	for _, tt := range tests {
+		os.WriteFile(filePath, []byte(hostsContent), 0644)
+		hostsEdit, _ := New(filePath, false)
+		err := hostsEdit.Pretty(tt.opts)
+		if err != nil {
+			t.Fatalf("%s: Pretty() error = %v", tt.name, err)
+		}
+		contentBytes, _ := os.ReadFile(filePath)
+		if string(contentBytes) != tt.want {
+			t.Errorf("%s: Pretty() wrote %q; want %q", tt.name, contentBytes, tt.want)
+		}
+	}
+}
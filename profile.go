@@ -0,0 +1,243 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import "strings"
+
+// Profiles are named groups of entries that are enabled and disabled
+// together, such as a set of overrides for a staging environment. A profile
+// is stored as the section "profile:<name>", so its entries sit between the
+// "# BEGIN profile:<name>" and "# END profile:<name>" markers. Disabling a
+// profile comments its entries out and enabling it restores them; lines
+// outside the profile are never changed.
+
+// profilePrefix starts the section names of profiles.
+const profilePrefix = "profile:"
+
+// Profile edits the entries of one named profile. It is returned by
+// HostsEdit.Profile and saves through the HostsEdit it came from.
+type Profile struct {
+	h    *HostsEdit
+	name string
+}
+
+// ProfileInfo describes a profile found in the file.
+type ProfileInfo struct {
+	Name    string
+	Entries int  // number of hosts, enabled or not
+	Enabled bool // false if the profile has entries and all are disabled
+}
+
+// Profile returns a handle for editing the named profile. The profile is
+// added to the end of the file by the first Edit if it does not exist. The
+// name must be a single word without "#".
+func (h *HostsEdit) Profile(name string) *Profile {
+	return &Profile{h: h, name: name}
+}
+
+// Name returns the name of the profile.
+func (p *Profile) Name() string {
+	return p.name
+}
+
+// Edit adds or updates host with the given IP address within the profile,
+// as EditInSection does. If the profile is disabled, the entry is written
+// disabled too, so it is enabled with the rest of the profile.
+func (p *Profile) Edit(host, ip string) error {
+	h := p.h
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	if h.lowercaseHosts {
+		host = lowerASCII(host)
+	}
+	section := profilePrefix + p.name
+	disabled := false
+	if begin, end, err := h.findSection(section); err == nil {
+		_, disabled = profileState(h.Lines[begin+1 : end])
+	}
+
+	prevIP, _ := h.Get(host)
+	changed := false
+	if disabled {
+		// 先从已禁用的条目中移除，避免同一主机留下两个禁用的条目
+		changed = h.removeInSection(section, host, func(line *Line) bool { return line.IsDisabled() })
+	}
+	set, err := h.setInSection(section, host, ip)
+	if err != nil {
+		return err
+	}
+	if !set && !changed {
+		return nil
+	}
+	if disabled {
+		begin, end, _ := h.findSection(section)
+		for _, line := range h.Lines[begin+1 : end] {
+			if isActive(line) {
+				line.IsComment = true
+				line.touch()
+			}
+		}
+	}
+	h.recordMappingChange(host, prevIP)
+	return h.saveToFile(operation{name: "Profile.Edit", host: host, ip: ip})
+}
+
+// Delete removes host from the entries of the profile, enabled or not.
+// Like Delete, it returns nil without writing the file if the profile has
+// no entry for host. If the profile does not exist, an error wrapping
+// ErrSectionNotFound is returned.
+func (p *Profile) Delete(host string) error {
+	h := p.h
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	section := profilePrefix + p.name
+	if _, _, err := h.findSection(section); err != nil {
+		return err
+	}
+	prevIP, _ := h.Get(host)
+	if !h.removeInSection(section, host, func(line *Line) bool { return isActive(line) || line.IsDisabled() }) {
+		return nil
+	}
+	h.recordMappingChange(host, prevIP)
+	return h.saveToFile(operation{name: "Profile.Delete", host: host})
+}
+
+// EnableProfile enables every disabled entry of the named profile and
+// saves the file once, only if an entry was enabled. If the profile does
+// not exist, an error wrapping ErrSectionNotFound is returned.
+func (h *HostsEdit) EnableProfile(name string) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	begin, end, err := h.findSection(profilePrefix + name)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for _, line := range h.Lines[begin+1 : end] {
+		if !line.IsComment {
+			continue
+		}
+		entry := commentedEntry(line)
+		if entry == nil {
+			continue
+		}
+		line.IsComment = false
+		line.UndefinedRowsRawStr = ""
+		line.setIP(entry.IP)
+		line.setHosts(entry.hosts)
+		line.touch()
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return h.saveToFile(operation{name: "EnableProfile"})
+}
+
+// DisableProfile disables every entry of the named profile by turning it
+// into a comment line, and saves the file once, only if an entry was
+// disabled. If the profile does not exist, an error wrapping
+// ErrSectionNotFound is returned.
+func (h *HostsEdit) DisableProfile(name string) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	begin, end, err := h.findSection(profilePrefix + name)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for _, line := range h.Lines[begin+1 : end] {
+		if isActive(line) {
+			line.IsComment = true
+			line.touch()
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return h.saveToFile(operation{name: "DisableProfile"})
+}
+
+// ListProfiles returns the profiles found in the file, in file order.
+func (h *HostsEdit) ListProfiles() []ProfileInfo {
+	var profiles []ProfileInfo
+	for _, line := range h.Lines {
+		if !isPlainComment(line) {
+			continue
+		}
+		fields := strings.Fields(line.UndefinedRowsRawStr)
+		if len(fields) != 2 || fields[0] != "BEGIN" || !strings.HasPrefix(fields[1], profilePrefix) {
+			continue
+		}
+		begin, end, err := h.findSection(fields[1])
+		if err != nil || h.Lines[begin] != line {
+			continue
+		}
+		entries, disabled := profileState(h.Lines[begin+1 : end])
+		profiles = append(profiles, ProfileInfo{
+			Name:    strings.TrimPrefix(fields[1], profilePrefix),
+			Entries: entries,
+			Enabled: !disabled,
+		})
+	}
+	return profiles
+}
+
+// profileState returns the number of hosts of the entries in lines and
+// whether there are entries and all of them are disabled.
+func profileState(lines []*Line) (entries int, disabled bool) {
+	active := 0
+	for _, line := range lines {
+		if isActive(line) {
+			active++
+			entries += len(line.hosts)
+		} else if line.IsComment {
+			if entry := commentedEntry(line); entry != nil {
+				entries += len(entry.hosts)
+			}
+		}
+	}
+	return entries, active == 0 && entries > 0
+}
+
+// removeInSection removes host from the lines of the named section for
+// which match returns true, dropping the lines left without hosts, and
+// reports whether it was removed from any line.
+func (h *HostsEdit) removeInSection(section, host string, match func(line *Line) bool) bool {
+	begin, end, err := h.findSection(section)
+	if err != nil {
+		return false
+	}
+	removed := false
+	for _, line := range h.Lines[begin+1 : end] {
+		if match(line) && line.RemoveHost(host) {
+			removed = true
+			if len(line.hosts) == 0 {
+				line.IsDelete = true
+			}
+		}
+	}
+	if removed {
+		h.dropDeleted()
+	}
+	return removed
+}
@@ -0,0 +1,84 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// 测试配置组的启用和禁用
+func TestProfiles(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n10.0.0.1 api.test\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	staging := hostsEdit.Profile("staging")
+	if err := staging.Edit("api.test", "10.1.0.1"); err != nil {
+		t.Fatalf("Profile(staging).Edit() error = %v", err)
+	}
+	staging.Edit("web.test", "10.1.0.2")
+	hostsEdit.Profile("office").Edit("intranet.test", "192.168.0.10")
+
+	if err := hostsEdit.DisableProfile("staging"); err != nil {
+		t.Fatalf("DisableProfile(staging) error = %v", err)
+	}
+	want := "127.0.0.1 localhost\n10.0.0.1 api.test\n" +
+		"# BEGIN profile:staging\n# 10.1.0.1 api.test\n# 10.1.0.2 web.test\n# END profile:staging\n" +
+		"# BEGIN profile:office\n192.168.0.10 intranet.test\n# END profile:office\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("DisableProfile() wrote %q; want %q", contentBytes, want)
+	}
+
+	// 禁用的配置组中新增的条目也是禁用的
+	if err := staging.Edit("api.test", "10.1.0.3"); err != nil {
+		t.Fatalf("Profile(staging).Edit() error = %v", err)
+	}
+	hostsEdit, _ = New(filePath, false)
+	wantProfiles := []ProfileInfo{{"staging", 2, false}, {"office", 1, true}}
+	if got := hostsEdit.ListProfiles(); !reflect.DeepEqual(got, wantProfiles) {
+		t.Errorf("ListProfiles() = %v; want %v", got, wantProfiles)
+	}
+
+	if err := hostsEdit.EnableProfile("staging"); err != nil {
+		t.Fatalf("EnableProfile(staging) error = %v", err)
+	}
+	want = "127.0.0.1 localhost\n10.0.0.1 api.test\n" +
+		"# BEGIN profile:staging\n10.1.0.2 web.test\n10.1.0.3 api.test\n# END profile:staging\n" +
+		"# BEGIN profile:office\n192.168.0.10 intranet.test\n# END profile:office\n"
+	contentBytes, _ = os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("EnableProfile() wrote %q; want %q", contentBytes, want)
+	}
+
+	if err := hostsEdit.Profile("office").Delete("intranet.test"); err != nil {
+		t.Fatalf("Profile(office).Delete() error = %v", err)
+	}
+	if got := hostsEdit.ListProfiles(); got[1].Entries != 0 || !got[1].Enabled {
+		t.Errorf("ListProfiles() after Delete = %v", got)
+	}
+
+	if err := hostsEdit.EnableProfile("home-lab"); !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("EnableProfile(home-lab) error = %v; want ErrSectionNotFound", err)
+	}
+}
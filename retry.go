@@ -0,0 +1,104 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"log/slog"
+	"os"
+	"time"
+)
+
+const (
+	defaultWriteAttempts = 5
+	defaultWriteDelay    = 50 * time.Millisecond
+)
+
+// WithWriteRetry sets how writes of the hosts file are retried on Windows
+// when the file is briefly held open by another process, such as an
+// antivirus scanner or the DNS client service: creating or replacing the
+// file is tried up to attempts times, waiting delay after the first failure
+// and twice as long after each further one. Only sharing violations, and
+// access denied errors for a file that is not read-only, are retried; other
+// errors are returned at once. Each retry is logged with WithLogger. The
+// default is 5 attempts with a delay of 50ms, about 750ms in total, and
+// attempts of 1 disables retrying. Writes are not retried on other systems.
+func WithWriteRetry(attempts int, delay time.Duration) Option {
+	return func(h *HostsEdit) {
+		h.writeAttempts = max(attempts, 1)
+		h.writeDelay = delay
+	}
+}
+
+// retryFileSystem is a fileSystem that retries Create, OpenFile and Rename
+// while isRetryableWriteError reports their error as retryable.
+type retryFileSystem struct {
+	fileSystem
+	attempts int
+	delay    time.Duration
+	logger   *slog.Logger
+}
+
+// retryFileSystem returns fsys wrapped with the retry settings of h.
+func (h *HostsEdit) retryFileSystem(fsys fileSystem) fileSystem {
+	r := retryFileSystem{fileSystem: fsys, attempts: defaultWriteAttempts, delay: defaultWriteDelay, logger: h.logger}
+	if h.writeAttempts > 0 {
+		r.attempts, r.delay = h.writeAttempts, h.writeDelay
+	}
+	if r.attempts == 1 {
+		return fsys
+	}
+	return r
+}
+
+func (r retryFileSystem) Create(name string) (file *os.File, err error) {
+	err = r.retry("create", name, func() error {
+		file, err = r.fileSystem.Create(name)
+		return err
+	})
+	return file, err
+}
+
+func (r retryFileSystem) OpenFile(name string, flag int, perm os.FileMode) (file *os.File, err error) {
+	err = r.retry("open", name, func() error {
+		file, err = r.fileSystem.OpenFile(name, flag, perm)
+		return err
+	})
+	return file, err
+}
+
+func (r retryFileSystem) Rename(oldpath, newpath string) error {
+	return r.retry("rename", newpath, func() error {
+		return r.fileSystem.Rename(oldpath, newpath)
+	})
+}
+
+// retry calls fn until it succeeds, fails with an error that is not
+// retryable, or has been called r.attempts times.
+func (r retryFileSystem) retry(op, name string, fn func() error) error {
+	delay := r.delay
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt == r.attempts || !isRetryableWriteError(err) {
+			return err
+		}
+		if r.logger != nil {
+			r.logger.Debug("retrying hosts file write", "file", name, "operation", op, "attempt", attempt, "delay", delay, "error", err)
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
@@ -0,0 +1,130 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// errSharing 模拟Windows的共享冲突错误
+var errSharing = errors.New("sharing violation")
+
+// busyFS 模拟前几次创建文件时被其他进程占用的文件系统
+type busyFS struct {
+	osFileSystem
+	failures *int
+	err      error
+}
+
+func (fs busyFS) Create(name string) (*os.File, error) {
+	if *fs.failures > 0 {
+		*fs.failures--
+		return nil, &os.PathError{Op: "open", Path: name, Err: fs.err}
+	}
+	return fs.osFileSystem.Create(name)
+}
+
+// 文件被其他进程打开时替换失败
+func (fs busyFS) Rename(oldpath, newpath string) error {
+	if *fs.failures > 0 {
+		*fs.failures--
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.err}
+	}
+	return fs.osFileSystem.Rename(oldpath, newpath)
+}
+
+func (fs busyFS) OpenFile(name string, flag int, perm os.FileMode) (*os.File, error) {
+	if *fs.failures > 0 {
+		*fs.failures--
+		return nil, &os.PathError{Op: "open", Path: name, Err: fs.err}
+	}
+	return fs.osFileSystem.OpenFile(name, flag, perm)
+}
+
+// 测试写入时重试共享冲突
+func TestWriteRetry(t *testing.T) {
+	defer func(f func(error) bool) { isRetryableWriteError = f }(isRetryableWriteError)
+	isRetryableWriteError = func(err error) bool { return errors.Is(err, errSharing) }
+
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	hostsEdit, _ := New(filePath, false, WithWriteRetry(3, time.Millisecond), WithLogger(logger))
+	failures := 2
+	hostsEdit.fs = busyFS{failures: &failures, err: errSharing}
+
+	err = hostsEdit.Edit("a.test", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Edit(a.test, 10.0.0.1) error = %v; want success after retries", err)
+	}
+	if n := strings.Count(logs.String(), "retrying hosts file write"); n != 2 {
+		t.Errorf("logged %d retries; want 2", n)
+	}
+
+	// 超过重试次数后返回错误
+	failures = 3
+	err = hostsEdit.Edit("b.test", "10.0.0.2")
+	if !errors.Is(err, errSharing) || failures != 0 {
+		t.Errorf("Edit(b.test, 10.0.0.2) error = %v with %d failures left; want sharing violation after 3 attempts", err, failures)
+	}
+
+	// 不可重试的错误立即返回
+	failures = 2
+	hostsEdit.fs = busyFS{failures: &failures, err: os.ErrPermission}
+	err = hostsEdit.Edit("c.test", "10.0.0.3")
+	if !errors.Is(err, os.ErrPermission) || failures != 1 {
+		t.Errorf("Edit(c.test, 10.0.0.3) error = %v with %d failures left; want permission error after 1 attempt", err, failures)
+	}
+}
+
+// 测试追加写入也通过fileSystem并重试共享冲突
+func TestAppendRetry(t *testing.T) {
+	defer func(f func(error) bool) { isRetryableWriteError = f }(isRetryableWriteError)
+	isRetryableWriteError = func(err error) bool { return errors.Is(err, errSharing) }
+
+	filePath, err := createTestHostsFile("127.0.0.1   localhost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false, WithInsertPolicy(InsertAppend), WithWriteRetry(3, time.Millisecond))
+	failures := 2
+	hostsEdit.fs = busyFS{failures: &failures, err: errSharing}
+	err = hostsEdit.Edit("a.test", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Edit(a.test, 10.0.0.1) error = %v; want success after retries", err)
+	}
+	if s := hostsEdit.LastWriteStrategy(); s != WriteAppend || failures != 0 {
+		t.Errorf("LastWriteStrategy() = %v with %d failures left; want %v after 2 retries", s, failures, WriteAppend)
+	}
+	contentBytes, _ := os.ReadFile(filePath)
+	if want := "127.0.0.1   localhost\n10.0.0.1 a.test\n"; string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+}
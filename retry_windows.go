@@ -0,0 +1,53 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+//go:build windows
+
+package hostedit
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// Windows error codes for a file opened by another process without sharing.
+const (
+	errorSharingViolation syscall.Errno = 32
+	errorLockViolation    syscall.Errno = 33
+)
+
+// isRetryableWriteError reports whether err is a sharing violation, or an
+// access denied error for a file that is not read-only, both usually caused
+// by another process holding the file open for a moment.
+var isRetryableWriteError = func(err error) bool {
+	if errors.Is(err, errorSharingViolation) || errors.Is(err, errorLockViolation) {
+		return true
+	}
+	if !errors.Is(err, syscall.ERROR_ACCESS_DENIED) {
+		return false
+	}
+	var path string
+	var pathErr *os.PathError
+	var linkErr *os.LinkError
+	if errors.As(err, &pathErr) {
+		path = pathErr.Path
+	} else if errors.As(err, &linkErr) {
+		path = linkErr.New
+	}
+	info, statErr := os.Stat(path)
+	return statErr == nil && info.Mode().Perm()&0o200 != 0
+}
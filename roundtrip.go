@@ -0,0 +1,58 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+)
+
+// VerifyRoundTrip loads the file at path with WithPreserveFormatting and
+// checks that saving it without modifications would reproduce it byte for
+// byte. The returned error reports the first line that differs.
+func VerifyRoundTrip(path string) error {
+	want, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	h, err := New(path, false, WithPreserveFormatting())
+	if err != nil {
+		return err
+	}
+	got := h.render()
+
+	if bytes.Equal(got, want) {
+		return nil
+	}
+
+	wantLines := bytes.SplitAfter(want, []byte("\n"))
+	gotLines := bytes.SplitAfter(got, []byte("\n"))
+	for i := 0; ; i++ {
+		var w, g []byte
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if !bytes.Equal(w, g) {
+			return fmt.Errorf("%s: round trip differs at line %d: got %q, want %q", path, i+1, g, w)
+		}
+	}
+}
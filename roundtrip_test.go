@@ -0,0 +1,133 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	hostedit "github.com/Deng-Xian-Sheng/go-hosts-edit-library"
+	"github.com/Deng-Xian-Sheng/go-hosts-edit-library/hostedittest"
+)
+
+// 测试VerifyRoundTrip对所有样本文件的字节级往返
+func TestVerifyRoundTrip(t *testing.T) {
+	fixtures, err := filepath.Glob("testdata/roundtrip/*.hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("no round trip fixtures found")
+	}
+	for _, fixture := range fixtures {
+		if err := hostedit.VerifyRoundTrip(fixture); err != nil {
+			t.Errorf("VerifyRoundTrip() error = %v", err)
+		}
+	}
+}
+
+// 测试保留格式模式下保存未修改的文件内容不变
+func TestPreserveFormattingSave(t *testing.T) {
+	hostsContent := "127.0.0.1\tlocalhost  \r\n\r\n#comment\r\n10.0.0.1 a.test b.test\r\n10.0.0.2 c.test"
+	filePath := hostedittest.WriteTempHosts(t, hostsContent)
+
+	hostsEdit, err := hostedit.New(filePath, false, hostedit.WithPreserveFormatting())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// 只修改一行，其他行应保持原样
+	err = hostsEdit.Edit("c.test", "10.0.0.3")
+	if err != nil {
+		t.Fatalf("Edit(c.test, 10.0.0.3) failed with error: %v", err)
+	}
+
+	hostedittest.AssertContent(t, filePath, "127.0.0.1\tlocalhost  \r\n\r\n#comment\r\n10.0.0.1 a.test b.test\r\n10.0.0.3 c.test\r\n")
+}
+
+// 测试非保留模式保存后的文件也能通过VerifyRoundTrip
+func TestVerifyRoundTripAfterSave(t *testing.T) {
+	filePath := hostedittest.WriteTempHosts(t, "\n  127.0.0.1   localhost\n#comment\n")
+
+	hostsEdit, _ := hostedit.New(filePath, false)
+	err := hostsEdit.Edit("newhost", "127.0.0.2")
+	if err != nil {
+		t.Fatalf("Edit(newhost, 127.0.0.2) failed with error: %v", err)
+	}
+	if err := hostedit.VerifyRoundTrip(filePath); err != nil {
+		t.Errorf("VerifyRoundTrip() error = %v", err)
+	}
+}
+
+// 测试宽松模式下保留同一行中重复的主机，修改该行时才去重
+func TestPreserveDuplicateHosts(t *testing.T) {
+	hostsContent := "10.0.0.1 foo foo bar\n10.0.0.2 baz baz\n"
+	filePath := hostedittest.WriteTempHosts(t, hostsContent)
+
+	hostsEdit, _ := hostedit.New(filePath, false)
+	// 修改其他行，重复的主机应原样写回
+	err := hostsEdit.Edit("qux", "10.0.0.3")
+	if err != nil {
+		t.Fatalf("Edit(qux, 10.0.0.3) failed with error: %v", err)
+	}
+	hostedittest.AssertContent(t, filePath, "10.0.0.3 qux\n10.0.0.1 foo foo bar\n10.0.0.2 baz baz\n")
+
+	err = hostsEdit.Edit("bar", "10.0.0.4")
+	if err != nil {
+		t.Fatalf("Edit(bar, 10.0.0.4) failed with error: %v", err)
+	}
+	err = hostsEdit.Edit("baz", "10.0.0.5")
+	if err != nil {
+		t.Fatalf("Edit(baz, 10.0.0.5) failed with error: %v", err)
+	}
+	hostedittest.AssertContent(t, filePath, "10.0.0.4 bar\n10.0.0.3 qux\n10.0.0.1 foo\n10.0.0.5 baz\n")
+
+	// 严格模式仍然报告重复的主机
+	_, err = hostedit.New(filePath, true)
+	if err != nil {
+		t.Fatalf("New(strict) error = %v", err)
+	}
+	os.WriteFile(filePath, []byte(hostsContent), 0644)
+	_, err = hostedit.New(filePath, true)
+	if !errors.Is(err, hostedit.ErrDuplicateHost) {
+		t.Errorf("New(strict) error = %v; want ErrDuplicateHost", err)
+	}
+}
+
+// 测试宽松模式保留条目尾部无法识别的标记，查找时不匹配这些标记
+func TestPreserveExtraTokens(t *testing.T) {
+	hostsContent := "10.0.0.1 a.test [alias] opt=1\n10.0.0.2 b.test\n"
+	filePath := hostedittest.WriteTempHosts(t, hostsContent)
+
+	hostsEdit, _ := hostedit.New(filePath, false)
+	if hostsEdit.Exists("[alias]") || hostsEdit.Exists("opt=1") {
+		t.Errorf("Exists() matched an extra token")
+	}
+	err := hostsEdit.Edit("a.test", "10.0.0.3")
+	if err != nil {
+		t.Fatalf("Edit(a.test, 10.0.0.3) failed with error: %v", err)
+	}
+	hostedittest.AssertContent(t, filePath, "10.0.0.3 a.test [alias] opt=1\n10.0.0.2 b.test\n")
+
+	// 严格模式拒绝这样的行
+	_, err = hostedit.New(filePath, true)
+	if !errors.Is(err, hostedit.ErrMalformedLine) {
+		t.Errorf("New(strict) error = %v; want ErrMalformedLine", err)
+	}
+}
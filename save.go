@@ -0,0 +1,598 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"syscall"
+)
+
+// BeforeSave registers fn to be called with the lines about to be written
+// before every save, whichever method triggers it. If fn returns an error,
+// nothing is written and the error is returned by the method that saved,
+// such as Edit, Delete or Save; the in-memory change that led to the save is
+// kept. Functions run in the order they were registered and must not modify
+// the lines.
+func (h *HostsEdit) BeforeSave(fn func(lines []*Line) error) {
+	h.beforeSave = append(h.beforeSave, fn)
+}
+
+func (h *HostsEdit) runBeforeSave() error {
+	for _, fn := range h.beforeSave {
+		if err := fn(h.Lines); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Save writes the hosts file to FilePath, or to the store of an instance
+// created with NewWithStore.
+//
+// The content is written to a temporary file next to TargetPath, which then
+// replaces TargetPath, so a reader sees either the old or the new file and
+// a failed write leaves the old one in place. The new file keeps the
+// permissions of the old one. When the file cannot be replaced because it
+// is busy or on another device, as a bind-mounted /etc/hosts in a
+// container, the content is written over it in place instead. Only new
+// lines are appended at the end of the file when the insert policy allows
+// it. LastWriteStrategy reports which of these happened. Edit, Delete and
+// every other method that saves write the file the same way.
+func (h *HostsEdit) Save() error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	return h.saveToFile(operation{name: "Save"})
+}
+
+// SaveAs writes the hosts file to path and makes path the FilePath used by
+// later saves, also for an instance created with NewWithStore, NewFromReader
+// or NewFromURL.
+func (h *HostsEdit) SaveAs(path string) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	h.FilePath = path
+	h.store = nil
+	// 新文件不能走追加的方式
+	h.synced = nil
+	return h.saveToFile(operation{name: "SaveAs"})
+}
+
+// SaveWithContext is like Save, but gives up when ctx is done before the
+// write completes. The content is written to a temporary file next to
+// TargetPath, which replaces TargetPath only once it is complete; if ctx is done
+// first, the temporary file is removed and ctx.Err() is returned. A write
+// blocked in the operating system cannot be interrupted, so the cleanup may
+// happen in the background after SaveWithContext has returned. For an
+// instance created with NewWithStore, Store.Save is called instead, and only
+// if ctx is not done by the time it would start.
+//
+// Replacing the file fails for a bind-mounted single file, as /etc/hosts is
+// in most containers. When the rename fails because the file is busy or on
+// another device, the content is written over FilePath in place instead,
+// with a single write, and LastWriteStrategy reports WriteInPlace.
+func (h *HostsEdit) SaveWithContext(ctx context.Context) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if h.store == nil && h.FilePath == "" {
+		return errNoFilePath
+	}
+	h.syncHostFields()
+	h.splitLongLines()
+	h.annotateChanges()
+	h.addHeader()
+	if err := h.runBeforeSave(); err != nil {
+		return err
+	}
+	if h.debounce != nil {
+		h.debounce.cancel()
+	}
+
+	logged := h.logWrite(operation{name: "SaveWithContext"})
+	content := h.render()
+	// 替换链接指向的文件，而不是链接本身
+	path, fsys, durable := h.TargetPath(), h.fileSystem(), h.syncWrites()
+	var strategy WriteStrategy
+	write := func(commit func() bool) (err error) {
+		strategy, err = writeFileAtomic(fsys, path, content, durable, commit)
+		return err
+	}
+	if store := h.store; store != nil {
+		write = func(commit func() bool) error {
+			if !commit() {
+				return errAbandoned
+			}
+			strategy = WriteStore
+			return store.Save(content)
+		}
+	}
+
+	var (
+		mu        sync.Mutex
+		abandoned bool
+		committed bool
+	)
+	done := make(chan error, 1)
+	go func() {
+		done <- write(func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			committed = !abandoned
+			return committed
+		})
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		mu.Lock()
+		if !committed {
+			abandoned = true
+			mu.Unlock()
+			logged(0, ctx.Err())
+			h.audit(operation{name: "SaveWithContext"}, ctx.Err())
+			return ctx.Err()
+		}
+		mu.Unlock()
+		// 已经开始替换文件，等待其完成
+		err = <-done
+	}
+	logged(strategy, err)
+	if err != nil {
+		err = h.wrapWriteError(err)
+		h.audit(operation{name: "SaveWithContext"}, err)
+		return err
+	}
+
+	h.lastWrite = strategy
+	h.markSynced(int64(len(content)))
+	h.notifyChange()
+	h.audit(operation{name: "SaveWithContext"}, nil)
+	return nil
+}
+
+// TargetPath returns the path of the file saves write to: FilePath with
+// symbolic links resolved, so that SaveWithContext replaces the file a link
+// points to and keeps the link. It is FilePath if FilePath does not exist
+// or its links cannot be resolved.
+func (h *HostsEdit) TargetPath() string {
+	if h.FilePath == "" {
+		return ""
+	}
+	target, err := filepath.EvalSymlinks(h.FilePath)
+	if err != nil {
+		return h.FilePath
+	}
+	return target
+}
+
+// CheckWritePermission reports whether the hosts file can be written, by
+// opening it for writing and creating a temporary file next to it, as saves
+// do to replace it, without changing either, so that a missing privilege
+// can be reported before any change is made. The error explains what is
+// missing and wraps the error of the operating system. It returns
+// ErrReadOnly for an instance created with WithReadOnly, and nil for an
+// instance created with NewWithStore.
+func (h *HostsEdit) CheckWritePermission() error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	if h.store != nil {
+		return nil
+	}
+	if h.FilePath == "" {
+		return errNoFilePath
+	}
+
+	path := h.TargetPath()
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return h.permissionError(path, path, err)
+	}
+	file.Close()
+
+	fsys, dir := h.fileSystem(), filepath.Dir(path)
+	tmp, err := fsys.CreateTemp(dir, "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return h.permissionError(path, dir, err)
+	}
+	tmp.Close()
+	return fsys.Remove(tmp.Name())
+}
+
+// permissionError explains err, the error of writing to name while checking
+// that the hosts file at path can be written.
+func (h *HostsEdit) permissionError(path, name string, err error) error {
+	// 不可变属性和WSL已经有各自的说明
+	if errors.Is(err, fs.ErrPermission) && !h.wsl && !isImmutable(path) {
+		return fmt.Errorf("%s is not writable, run as root or administrator: %w", name, err)
+	}
+	return h.wrapWriteError(err)
+}
+
+// errAbandoned is returned by writeFileAtomic when commit declines to
+// replace the file.
+var errAbandoned = errors.New("write abandoned")
+
+// writeFileAtomic writes content to a temporary file in the same directory
+// as path and renames it over path if commit returns true. The temporary
+// file is removed if anything fails or commit returns false. If the rename
+// fails because path cannot be replaced, content is written over path in
+// place instead. If durable is set, the file is synced to disk before it is
+// renamed, and the directory after.
+func writeFileAtomic(fsys fileSystem, path string, content []byte, durable bool, commit func() bool) (strategy WriteStrategy, err error) {
+	mode := os.FileMode(0o644)
+	if info, err := fsys.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := fsys.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			fsys.Remove(tmp.Name())
+		}
+	}()
+
+	_, err = tmp.Write(content)
+	if err != nil {
+		return 0, err
+	}
+	if durable {
+		err = tmp.Sync()
+		if err != nil {
+			return 0, err
+		}
+	}
+	err = tmp.Chmod(mode)
+	if err != nil {
+		return 0, err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	if !commit() {
+		return 0, errAbandoned
+	}
+	err = fsys.Rename(tmp.Name(), path)
+	if err == nil {
+		if durable {
+			// 同步目录，确保重命名在断电后仍然有效
+			return WriteAtomic, syncDir(filepath.Dir(path))
+		}
+		return WriteAtomic, nil
+	}
+	if !errors.Is(err, syscall.EXDEV) && !errors.Is(err, syscall.EBUSY) {
+		return 0, err
+	}
+
+	// 容器中绑定挂载的单个文件不能被替换，退回到原地重写
+	fsys.Remove(tmp.Name())
+	err = writeFile(fsys, path, content, durable)
+	if err != nil {
+		return 0, err
+	}
+	return WriteInPlace, nil
+}
+
+// saveToFile writes the current hosts file configuration back to disk.
+// op is the call that made the change being saved.
+func (h *HostsEdit) saveToFile(op operation) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	h.syncHostFields()
+	h.splitLongLines()
+	h.annotateChanges()
+	if h.appendStart() < 0 {
+		h.addHeader()
+	}
+	if err := h.runBeforeSave(); err != nil {
+		return err
+	}
+	if h.debounce != nil {
+		h.debounce.cancel()
+	}
+
+	logged := h.logWrite(op)
+	strategy, err := h.write()
+	logged(strategy, err)
+	if err != nil {
+		err = h.wrapWriteError(err)
+		h.audit(op, err)
+		return err
+	}
+
+	h.lastWrite = strategy
+	h.notifyChange()
+	h.audit(op, nil)
+	return nil
+}
+
+// wrapWriteError adds a hint to a permission error: when writing the
+// Windows hosts file from WSL, or for a file with the immutable attribute.
+func (h *HostsEdit) wrapWriteError(err error) error {
+	if !errors.Is(err, fs.ErrPermission) {
+		return err
+	}
+	if h.wsl {
+		return fmt.Errorf("%w (the Windows hosts file can only be changed from WSL when the WSL terminal runs as administrator on Windows)", err)
+	}
+	// 即使是root也无法修改带有不可变属性的文件
+	if path := h.TargetPath(); h.store == nil && errors.Is(err, syscall.EPERM) && isImmutable(path) {
+		return fmt.Errorf("%w: %s cannot be changed until the attribute is removed with \"chattr -i %s\": %w", ErrImmutableFile, path, path, err)
+	}
+	return err
+}
+
+// write writes the lines to the file, appending new lines if possible, and
+// marks them as synced.
+func (h *HostsEdit) write() (WriteStrategy, error) {
+	if start := h.appendStart(); start >= 0 {
+		size, err := h.appendToFile(start)
+		if err == nil {
+			h.markSynced(size)
+			return WriteAppend, nil
+		}
+		if !errors.Is(err, errNotAppendable) {
+			return 0, err
+		}
+	}
+
+	if h.store == nil && h.FilePath == "" {
+		return 0, errNoFilePath
+	}
+	content := h.render()
+	strategy, err := h.contentWriter()(content)
+	if err != nil {
+		return 0, err
+	}
+
+	h.markSynced(int64(len(content)))
+	return strategy, nil
+}
+
+// contentWriter returns the function that replaces the whole content of
+// the hosts file, for the store of h or the file at FilePath, and reports
+// how it was written. The file is replaced atomically as writeFileAtomic
+// describes.
+func (h *HostsEdit) contentWriter() func(content []byte) (WriteStrategy, error) {
+	if store := h.store; store != nil {
+		return func(content []byte) (WriteStrategy, error) {
+			return WriteStore, store.Save(content)
+		}
+	}
+	// 替换链接指向的文件，而不是链接本身
+	path, fsys, durable := h.TargetPath(), h.fileSystem(), h.syncWrites()
+	return func(content []byte) (WriteStrategy, error) {
+		return writeFileAtomic(fsys, path, content, durable, func() bool { return true })
+	}
+}
+
+// writeFile truncates the file at path and writes content to it, and syncs
+// it to disk if durable is set.
+func writeFile(fsys fileSystem, path string, content []byte, durable bool) error {
+	file, err := fsys.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(content)
+	if err != nil || !durable {
+		return err
+	}
+	return file.Sync()
+}
+
+// syncDir syncs the directory at path to disk, so that a rename in it is
+// persisted. Directories cannot be synced on Windows, where it does nothing.
+func syncDir(path string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
+
+// syncWrites reports whether writes are synced to disk, as set by WithSync.
+func (h *HostsEdit) syncWrites() bool {
+	if h.fsyncSet {
+		return h.fsync
+	}
+	return h.wsl || filepath.Clean(h.FilePath) == platformPath()
+}
+
+// errNoFilePath is returned when saving an instance that has neither a file
+// nor a store.
+var errNoFilePath = errors.New("no file path to save to, use SaveAs")
+
+// errNotAppendable is returned by appendToFile when the file changed on disk
+// since it was last synced and must be rewritten instead.
+var errNotAppendable = errors.New("file cannot be appended to")
+
+// appendStart returns the index of the first line added since the last sync
+// if all lines before it are unchanged, or -1 if the file must be rewritten.
+func (h *HostsEdit) appendStart() int {
+	if h.insertPolicy != InsertAppend || h.store != nil || h.synced == nil || len(h.Lines) <= len(h.synced) {
+		return -1
+	}
+	for i, line := range h.synced {
+		if h.Lines[i] != line || line.dirty {
+			return -1
+		}
+	}
+	return len(h.synced)
+}
+
+// appendToFile writes the lines from index start onward to the end of the
+// file and returns the new file size.
+func (h *HostsEdit) appendToFile(start int) (int64, error) {
+	file, err := h.fileSystem().OpenFile(h.TargetPath(), os.O_RDWR|os.O_APPEND, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+	if size != h.syncedSize {
+		return 0, errNotAppendable
+	}
+
+	var b bytes.Buffer
+	// 原文件末尾没有换行符时先补上，否则新行会接在最后一行后面
+	if size > 0 {
+		last := make([]byte, 1)
+		_, err = file.ReadAt(last, size-1)
+		if err != nil {
+			return 0, err
+		}
+		if last[0] != '\n' {
+			b.WriteString(h.eol)
+		}
+	}
+	h.renderLines(&b, start)
+
+	n, err := file.Write(b.Bytes())
+	if err != nil {
+		return 0, err
+	}
+	if h.syncWrites() {
+		err = file.Sync()
+		if err != nil {
+			return 0, err
+		}
+	}
+	return size + int64(n), nil
+}
+
+// syncHostFields applies the changes made directly to the deprecated
+// Line.Host field of the lines.
+func (h *HostsEdit) syncHostFields() {
+	for _, line := range h.Lines {
+		line.syncHostField()
+	}
+}
+
+// splitLongLines splits the entry lines with more hosts than allowed by
+// WithMaxHostsPerLine.
+func (h *HostsEdit) splitLongLines() {
+	n := h.maxHostsPerLine
+	if n <= 0 {
+		return
+	}
+	var lines []*Line
+	for i, line := range h.Lines {
+		if line.UndefinedRowsRawStr != "" || len(line.hosts) <= n {
+			if lines != nil {
+				lines = append(lines, line)
+			}
+			continue
+		}
+		if lines == nil {
+			lines = append([]*Line(nil), h.Lines[:i]...)
+		}
+		hosts := line.Hosts()
+		line.setHosts(hosts[:n:n])
+		line.touch()
+		lines = append(lines, line)
+		for j := n; j < len(hosts); j += n {
+			part := NewLine(line.IP, hosts[j:min(j+n, len(hosts))]...)
+			part.IsComment, part.indent = line.IsComment, line.indent
+			lines = append(lines, part)
+		}
+	}
+	if lines != nil {
+		h.Lines = lines
+	}
+}
+
+// markSynced records the current lines as the content of the file on disk.
+func (h *HostsEdit) markSynced(size int64) {
+	for i, line := range h.Lines {
+		if h.preserve && !line.verbatim {
+			line.raw = formatLine(line, h.renderConfig)
+			line.eol = h.lineEOL(i, line)
+			line.verbatim = true
+		}
+		line.dirty = false
+	}
+	h.synced = append(h.synced[:0:0], h.Lines...)
+	h.syncedSize = size
+}
+
+// render returns the content of the hosts file as saveToFile would write it.
+func (h *HostsEdit) render() []byte {
+	var b bytes.Buffer
+	if h.bom {
+		b.Write(utf8BOM)
+	}
+	h.renderLines(&b, 0)
+	return b.Bytes()
+}
+
+// renderLines writes the lines from index start onward to b.
+func (h *HostsEdit) renderLines(b *bytes.Buffer, start int) {
+	for i := start; i < len(h.Lines); i++ {
+		line := h.Lines[i]
+		if h.preserve && line.verbatim {
+			b.WriteString(line.raw)
+		} else {
+			b.WriteString(formatLine(line, h.renderConfig))
+		}
+		b.WriteString(h.lineEOL(i, line))
+	}
+}
+
+// lineEOL returns the line ending written after the line at index i.
+func (h *HostsEdit) lineEOL(i int, line *Line) string {
+	if !h.preserve || !line.verbatim {
+		return h.eol
+	}
+	// 保持原文件末尾没有换行符的状态
+	if line.eol == "" && i < len(h.Lines)-1 {
+		return h.eol
+	}
+	return line.eol
+}
@@ -0,0 +1,543 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+package hostedit
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// 测试Save方法
+func TestSave(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1   localhost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	hostsEdit.Lines = append(hostsEdit.Lines, NewLine("10.0.0.1", "api.test"))
+	err = hostsEdit.Save()
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	contentBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read hosts file: %v", err)
+	}
+	want := "127.0.0.1 localhost\n10.0.0.1 api.test\n"
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+}
+
+// 测试SaveWithContext方法
+func TestSaveWithContext(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "hosts")
+	err := os.WriteFile(filePath, []byte("127.0.0.1 localhost\n"), 0o640)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hostsEdit, _ := New(filePath, false)
+	hostsEdit.Lines = append(hostsEdit.Lines, NewLine("10.0.0.1", "api.test"))
+
+	// 已取消的context不写文件
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = hostsEdit.SaveWithContext(ctx)
+	if err != context.Canceled {
+		t.Errorf("SaveWithContext() error = %v; want %v", err, context.Canceled)
+	}
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != "127.0.0.1 localhost\n" {
+		t.Errorf("cancelled SaveWithContext changed content to %q", contentBytes)
+	}
+
+	err = hostsEdit.SaveWithContext(context.Background())
+	if err != nil {
+		t.Fatalf("SaveWithContext() error = %v", err)
+	}
+	contentBytes, _ = os.ReadFile(filePath)
+	if string(contentBytes) != "127.0.0.1 localhost\n10.0.0.1 api.test\n" {
+		t.Errorf("saved content = %q", contentBytes)
+	}
+
+	// 保留原文件权限，且不留下临时文件
+	info, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o640 {
+		t.Errorf("file mode = %v; want %v", info.Mode().Perm(), os.FileMode(0o640))
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries; want only the hosts file", len(entries))
+	}
+}
+
+// renameErrorFS 模拟重命名失败的文件系统
+type renameErrorFS struct {
+	osFileSystem
+	err error
+}
+
+func (fs renameErrorFS) Rename(oldpath, newpath string) error {
+	return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.err}
+}
+
+// recordingFS 记录删除的文件，报告0600权限，并可以模拟重命名失败
+type recordingFS struct {
+	osFileSystem
+	renameErr error
+	removed   *[]string
+}
+
+func (fs recordingFS) Rename(oldpath, newpath string) error {
+	if fs.renameErr != nil {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.renameErr}
+	}
+	return os.Rename(oldpath, newpath)
+}
+
+func (fs recordingFS) Remove(name string) error {
+	*fs.removed = append(*fs.removed, name)
+	return os.Remove(name)
+}
+
+func (fs recordingFS) Stat(name string) (os.FileInfo, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	return modeFileInfo{info}, nil
+}
+
+// modeFileInfo 报告0600权限的FileInfo
+type modeFileInfo struct {
+	os.FileInfo
+}
+
+func (modeFileInfo) Mode() os.FileMode {
+	return 0o600
+}
+
+// 测试原子写入通过fileSystem获取权限和删除临时文件
+func TestWriteFileAtomicFileSystem(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	var removed []string
+	fsys := recordingFS{renameErr: syscall.EXDEV, removed: &removed}
+	strategy, err := writeFileAtomic(fsys, filePath, []byte("10.0.0.1 a.test\n"), false, func() bool { return true })
+	if err != nil || strategy != WriteInPlace {
+		t.Fatalf("writeFileAtomic() = %v, %v; want the in-place fallback", strategy, err)
+	}
+	if len(removed) != 1 || !strings.Contains(filepath.Base(removed[0]), ".tmp") {
+		t.Errorf("removed %v; want the temporary file", removed)
+	}
+
+	// 新文件的权限来自fileSystem的Stat
+	fsys.renameErr = nil
+	strategy, err = writeFileAtomic(fsys, filePath, []byte("10.0.0.2 b.test\n"), false, func() bool { return true })
+	if err != nil || strategy != WriteAtomic {
+		t.Fatalf("writeFileAtomic() = %v, %v; want %v", strategy, err, WriteAtomic)
+	}
+	if info, _ := os.Stat(filePath); runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+		t.Errorf("file mode = %v; want 0600 from Stat", info.Mode().Perm())
+	}
+}
+
+// 测试SaveWithContext在无法替换文件时退回到原地重写
+func TestSaveWithContextRenameFallback(t *testing.T) {
+	tests := []struct {
+		err      error
+		fallback bool
+	}{
+		{syscall.EXDEV, true},
+		{syscall.EBUSY, true},
+		{syscall.EACCES, false},
+	}
+	for _, tt := range tests {
+		hostsContent := "127.0.0.1 localhost\n"
+		filePath, err := createTestHostsFile(hostsContent)
+		if err != nil {
+			t.Fatalf("Failed to create test hosts file: %v", err)
+		}
+		defer os.Remove(filePath)
+
+		hostsEdit, _ := New(filePath, false)
+		hostsEdit.fs = renameErrorFS{err: tt.err}
+		hostsEdit.GetLineForHost("localhost").AddHost("myapp.local")
+
+		err = hostsEdit.SaveWithContext(context.Background())
+		contentBytes, _ := os.ReadFile(filePath)
+		if tt.fallback {
+			if err != nil {
+				t.Errorf("SaveWithContext() with %v error = %v", tt.err, err)
+			}
+			if string(contentBytes) != "127.0.0.1 localhost myapp.local\n" {
+				t.Errorf("SaveWithContext() with %v wrote %q", tt.err, contentBytes)
+			}
+			if s := hostsEdit.LastWriteStrategy(); s != WriteInPlace {
+				t.Errorf("LastWriteStrategy() = %v; want %v", s, WriteInPlace)
+			}
+		} else {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("SaveWithContext() with %v error = %v", tt.err, err)
+			}
+			if string(contentBytes) != hostsContent {
+				t.Errorf("failed SaveWithContext() changed the file to %q", contentBytes)
+			}
+		}
+
+		tmpFiles, _ := filepath.Glob(filepath.Join(filepath.Dir(filePath), "."+filepath.Base(filePath)+".tmp*"))
+		if len(tmpFiles) != 0 {
+			t.Errorf("temporary files left behind: %v", tmpFiles)
+		}
+	}
+
+	// 正常情况下使用原子替换
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+	hostsEdit, _ := New(filePath, false)
+	if err := hostsEdit.SaveWithContext(context.Background()); err != nil {
+		t.Fatalf("SaveWithContext() error = %v", err)
+	}
+	if s := hostsEdit.LastWriteStrategy(); s != WriteAtomic {
+		t.Errorf("LastWriteStrategy() = %v; want %v", s, WriteAtomic)
+	}
+}
+
+// 测试Edit和Delete原子替换文件，无法替换时原地写入
+func TestSaveAtomic(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+	os.Chmod(filePath, 0o600)
+
+	hostsEdit, _ := New(filePath, false)
+	before, _ := os.Stat(filePath)
+	if err := hostsEdit.Edit("a.test", "10.0.0.1"); err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+	after, _ := os.Stat(filePath)
+	if os.SameFile(before, after) {
+		t.Errorf("Edit() wrote the file in place; want it replaced")
+	}
+	if err := hostsEdit.Delete("localhost"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	after, _ = os.Stat(filePath)
+	if runtime.GOOS != "windows" && after.Mode().Perm() != 0o600 {
+		t.Errorf("replaced file mode = %v; want 0600 kept", after.Mode().Perm())
+	}
+	if s := hostsEdit.LastWriteStrategy(); s != WriteAtomic {
+		t.Errorf("LastWriteStrategy() after Delete() = %v; want %v", s, WriteAtomic)
+	}
+	tmpFiles, _ := filepath.Glob(filepath.Join(filepath.Dir(filePath), "."+filepath.Base(filePath)+".tmp*"))
+	if len(tmpFiles) != 0 {
+		t.Errorf("temporary files left behind: %v", tmpFiles)
+	}
+
+	// 绑定挂载的文件不能被替换，退回到原地写入
+	hostsEdit.fs = renameErrorFS{err: syscall.EBUSY}
+	if err := hostsEdit.Edit("b.test", "10.0.0.2"); err != nil {
+		t.Fatalf("Edit() with EBUSY error = %v", err)
+	}
+	inPlace, _ := os.Stat(filePath)
+	if !os.SameFile(after, inPlace) {
+		t.Errorf("Edit() with EBUSY replaced the file; want it written in place")
+	}
+	if s := hostsEdit.LastWriteStrategy(); s != WriteInPlace {
+		t.Errorf("LastWriteStrategy() with EBUSY = %v; want %v", s, WriteInPlace)
+	}
+
+	// 其他错误不退回，文件保持不变
+	hostsEdit.fs = renameErrorFS{err: syscall.EACCES}
+	if err := hostsEdit.Edit("c.test", "10.0.0.3"); !errors.Is(err, syscall.EACCES) {
+		t.Errorf("Edit() with EACCES error = %v; want EACCES", err)
+	}
+	contentBytes, _ := os.ReadFile(filePath)
+	if want := "10.0.0.2 b.test\n10.0.0.1 a.test\n"; string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+}
+
+// 测试BeforeSave钩子可以阻止写入
+func TestBeforeSave(t *testing.T) {
+	hostsContent := "127.0.0.1 localhost\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+
+	var calls []string
+	hostsEdit.BeforeSave(func(lines []*Line) error {
+		calls = append(calls, "first")
+		return nil
+	})
+	errPolicy := errors.New("only private addresses allowed")
+	hostsEdit.BeforeSave(func(lines []*Line) error {
+		calls = append(calls, "policy")
+		for _, line := range lines {
+			ip := net.ParseIP(line.IP)
+			if ip != nil && !ip.IsPrivate() && !ip.IsLoopback() {
+				return errPolicy
+			}
+		}
+		return nil
+	})
+
+	err = hostsEdit.Edit("public.test", "8.8.8.8")
+	if !errors.Is(err, errPolicy) {
+		t.Errorf("Edit(public.test, 8.8.8.8) error = %v; want %v", err, errPolicy)
+	}
+	if len(calls) != 2 || calls[0] != "first" || calls[1] != "policy" {
+		t.Errorf("hooks called as %v; want [first policy]", calls)
+	}
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != hostsContent {
+		t.Errorf("vetoed save changed the file to %q", contentBytes)
+	}
+
+	hostsEdit.Delete("public.test")
+	err = hostsEdit.Edit("private.test", "10.0.0.1")
+	if err != nil {
+		t.Errorf("Edit(private.test, 10.0.0.1) error = %v", err)
+	}
+}
+
+// 测试WithReadOnly选项阻止所有写操作
+func TestWithReadOnly(t *testing.T) {
+	hostsContent := "127.0.0.1 localhost\n# 10.0.0.1 off.test\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, err := New(filePath, false, WithReadOnly())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	writes := map[string]func() error{
+		"Edit":              func() error { return hostsEdit.Edit("a.test", "10.0.0.1") },
+		"Delete":            func() error { return hostsEdit.Delete("localhost") },
+		"DeleteLine":        func() error { return hostsEdit.DeleteLine(0) },
+		"ReplaceIP":         func() error { _, err := hostsEdit.ReplaceIP("127.0.0.1", "127.0.0.2"); return err },
+		"SetInlineComment":  func() error { return hostsEdit.SetInlineComment("localhost", "x") },
+		"UncommentAll":      func() error { return hostsEdit.UncommentAll() },
+		"CompactWith":       func() error { _, err := hostsEdit.CompactWith(CompactOptions{}); return err },
+		"NormalizeHosts":    func() error { return hostsEdit.NormalizeHosts() },
+		"EnsureIPv6Default": func() error { _, err := hostsEdit.EnsureIPv6Defaults(); return err },
+		"AddSection":        func() error { return hostsEdit.AddSection("myapp") },
+		"Save":              func() error { return hostsEdit.Save() },
+		"SaveAs":            func() error { return hostsEdit.SaveAs(filePath + ".copy") },
+		"SaveWithContext":   func() error { return hostsEdit.SaveWithContext(context.Background()) },
+		"SaveDebounced":     func() error { return hostsEdit.SaveDebounced(time.Millisecond) },
+		"ImportYAML":        func() error { return hostsEdit.ImportYAML(strings.NewReader("- ip: 10.0.0.1\n  hosts: [a.test]\n")) },
+	}
+	for name, write := range writes {
+		if err := write(); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("%s() error = %v; want ErrReadOnly", name, err)
+		}
+	}
+
+	if len(hostsEdit.Lines) != 2 || hostsEdit.FilePath != filePath {
+		t.Errorf("read-only instance was changed: %d lines, FilePath %q", len(hostsEdit.Lines), hostsEdit.FilePath)
+	}
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != hostsContent {
+		t.Errorf("read-only file changed to %q", contentBytes)
+	}
+	if _, err := os.Stat(filePath + ".copy"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("SaveAs() created a file on a read-only instance")
+	}
+}
+
+// 测试WithMaxHostsPerLine选项在保存时拆分长行
+func TestWithMaxHostsPerLine(t *testing.T) {
+	hostsContent := "127.0.0.1 localhost\n0.0.0.0 a.test b.test c.test d.test e.test # blocked\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false, WithMaxHostsPerLine(2))
+	err = hostsEdit.Save()
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	want := "127.0.0.1 localhost\n0.0.0.0 a.test b.test # blocked\n0.0.0.0 c.test d.test\n0.0.0.0 e.test\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+	if len(hostsEdit.Lines) != 4 {
+		t.Errorf("len(Lines) = %d after Save; want 4", len(hostsEdit.Lines))
+	}
+}
+
+// 测试WithSync选项和默认的同步设置
+func TestWithSync(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	if hostsEdit.syncWrites() {
+		t.Errorf("syncWrites() = true for %s; want false by default", filePath)
+	}
+	if h := (&HostsEdit{FilePath: platformPath()}); !h.syncWrites() {
+		t.Errorf("syncWrites() = false for %s; want true by default", platformPath())
+	}
+
+	// 所有写入方式在同步时都能正常保存
+	hostsEdit, _ = New(filePath, false, WithSync(true), WithInsertPolicy(InsertAppend))
+	err = hostsEdit.Edit("a.test", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Edit(a.test, 10.0.0.1) failed with error: %v", err)
+	}
+	err = hostsEdit.Delete("localhost")
+	if err != nil {
+		t.Fatalf("Delete(localhost) failed with error: %v", err)
+	}
+	hostsEdit.GetLineForHost("a.test").AddHost("b.test")
+	err = hostsEdit.SaveWithContext(context.Background())
+	if err != nil {
+		t.Fatalf("SaveWithContext() error = %v", err)
+	}
+	want := "10.0.0.1 a.test b.test\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+}
+
+// 测试通过符号链接保存时保留链接
+func TestSaveThroughSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "hosts.real")
+	link := filepath.Join(dir, "hosts")
+	err := os.WriteFile(target, []byte("127.0.0.1 localhost\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Symlink(target, link)
+	if err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	hostsEdit, _ := New(link, false)
+	// 临时目录本身也可能是链接
+	want, _ := filepath.EvalSymlinks(target)
+	if got := hostsEdit.TargetPath(); got != want {
+		t.Errorf("TargetPath() = %q; want %q", got, want)
+	}
+	err = hostsEdit.Edit("a.test", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Edit(a.test, 10.0.0.1) failed with error: %v", err)
+	}
+	hostsEdit.GetLineForHost("a.test").AddHost("b.test")
+	err = hostsEdit.SaveWithContext(context.Background())
+	if err != nil {
+		t.Fatalf("SaveWithContext() error = %v", err)
+	}
+
+	info, err := os.Lstat(link)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("hosts is no longer a symlink after saving: %v", err)
+	}
+	want = "10.0.0.1 a.test b.test\n127.0.0.1 localhost\n"
+	contentBytes, _ := os.ReadFile(target)
+	if string(contentBytes) != want {
+		t.Errorf("link target content = %q; want %q", contentBytes, want)
+	}
+}
+
+// 测试CheckWritePermission方法
+func TestCheckWritePermission(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	if err := hostsEdit.CheckWritePermission(); err != nil {
+		t.Errorf("CheckWritePermission() error = %v", err)
+	}
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != "127.0.0.1 localhost\n" {
+		t.Errorf("CheckWritePermission() changed the file to %q", contentBytes)
+	}
+
+	hostsEdit, _ = New(filePath, false, WithReadOnly())
+	if err := hostsEdit.CheckWritePermission(); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("CheckWritePermission() error = %v; want ErrReadOnly", err)
+	}
+
+	// root不受文件权限限制，此时跳过
+	if os.Geteuid() == 0 {
+		t.Skip("file permissions do not apply to root")
+	}
+	os.Chmod(filePath, 0o444)
+	hostsEdit, _ = New(filePath, false)
+	err = hostsEdit.CheckWritePermission()
+	if !errors.Is(err, os.ErrPermission) || !strings.Contains(err.Error(), "run as root") {
+		t.Errorf("CheckWritePermission() error = %v; want a permission error with a hint", err)
+	}
+
+	// 目录不可写时无法替换文件
+	os.Chmod(filePath, 0o644)
+	dir := t.TempDir()
+	dirPath := filepath.Join(dir, "hosts")
+	os.WriteFile(dirPath, []byte("127.0.0.1 localhost\n"), 0o644)
+	os.Chmod(dir, 0o555)
+	defer os.Chmod(dir, 0o755)
+	hostsEdit, _ = New(dirPath, false)
+	err = hostsEdit.CheckWritePermission()
+	if !errors.Is(err, os.ErrPermission) || !strings.Contains(err.Error(), dir+" is not writable") {
+		t.Errorf("CheckWritePermission() in a read-only directory error = %v; want a permission error naming it", err)
+	}
+}
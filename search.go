@@ -0,0 +1,133 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import "strings"
+
+// SearchField is the part of a line a search result matched.
+type SearchField int
+
+const (
+	// FieldHost is a host of an entry, including a disabled entry.
+	FieldHost SearchField = iota
+	// FieldIP is the IP address of an entry, including a disabled entry.
+	FieldIP
+	// FieldComment is the text of a comment line or an inline comment.
+	FieldComment
+)
+
+func (f SearchField) String() string {
+	switch f {
+	case FieldHost:
+		return "host"
+	case FieldIP:
+		return "ip"
+	case FieldComment:
+		return "comment"
+	}
+	return "unknown"
+}
+
+// SearchResult is a field of a line that contains the query of Search.
+// Text[Start:End] is the first occurrence of the query in the field.
+type SearchResult struct {
+	Line  int // index of the line, as for LineAt
+	Field SearchField
+	Text  string // the whole field
+	Start int
+	End   int
+}
+
+// Search returns the hosts, IP addresses and comments that contain q,
+// ignoring the case of ASCII letters, in line order and, within a line, in
+// the order the fields are written. Each matching field is reported once.
+// Malformed lines are not searched. An empty q matches nothing.
+func (h *HostsEdit) Search(q string) []SearchResult {
+	if q == "" {
+		return nil
+	}
+	q = lowerASCII(q)
+
+	var results []SearchResult
+	match := func(i int, field SearchField, text string) {
+		if start := indexFoldASCII(text, q); start >= 0 {
+			results = append(results, SearchResult{Line: i, Field: field, Text: text, Start: start, End: start + len(q)})
+		}
+	}
+	for i, line := range h.Lines {
+		if line.UndefinedRowsRawStr != "" {
+			if line.IsComment {
+				match(i, FieldComment, line.UndefinedRowsRawStr)
+			}
+			continue
+		}
+		if line.IP == "" {
+			continue
+		}
+		match(i, FieldIP, line.IP)
+		for _, host := range line.hosts {
+			match(i, FieldHost, host)
+		}
+		if line.InlineComment != "" {
+			match(i, FieldComment, line.InlineComment)
+		}
+	}
+	return results
+}
+
+// indexFoldASCII returns the index of the first occurrence of the lowercase
+// string sub in s, ignoring the case of ASCII letters in s, or -1.
+func indexFoldASCII(s, sub string) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if equalFoldASCII(s[i:i+len(sub)], sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+// SubdomainsOf returns the effective entry of every host that is domain or
+// ends with "." and domain, such as api.example.com for example.com but not
+// notexample.com. Hosts are compared ignoring the case of ASCII letters and
+// a trailing dot. The entries are in file order, one per host, also for
+// hosts that share a line.
+func (h *HostsEdit) SubdomainsOf(domain string) []Entry {
+	domain = lowerASCII(strings.TrimSuffix(domain, "."))
+	if domain == "" {
+		return nil
+	}
+
+	var entries []Entry
+	seen := make(map[string]struct{})
+	for _, line := range h.Lines {
+		if !isActive(line) {
+			continue
+		}
+		for _, host := range line.hosts {
+			name := lowerASCII(strings.TrimSuffix(host, "."))
+			if name != domain && !strings.HasSuffix(name, "."+domain) {
+				continue
+			}
+			if _, ok := seen[name]; ok {
+				continue
+			}
+			seen[name] = struct{}{}
+			entries = append(entries, Entry{IP: line.IP, Host: host})
+		}
+	}
+	return entries
+}
@@ -0,0 +1,78 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+// 测试Search方法
+func TestSearch(t *testing.T) {
+	hostsContent := "# Office servers\n10.0.0.1 Api.Office.test # office API\nnot an entry office\n# 10.0.0.2 old.office.test\n127.0.0.1 localhost\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	want := []SearchResult{
+		{Line: 0, Field: FieldComment, Text: "Office servers", Start: 0, End: 6},
+		{Line: 1, Field: FieldHost, Text: "Api.Office.test", Start: 4, End: 10},
+		{Line: 1, Field: FieldComment, Text: "office API", Start: 0, End: 6},
+		{Line: 3, Field: FieldHost, Text: "old.office.test", Start: 4, End: 10},
+	}
+	if got := hostsEdit.Search("OFFICE"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Search(OFFICE) = %+v; want %+v", got, want)
+	}
+
+	want = []SearchResult{{Line: 1, Field: FieldIP, Text: "10.0.0.1", Start: 0, End: 8}}
+	if got := hostsEdit.Search("10.0.0.1"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Search(10.0.0.1) = %+v; want %+v", got, want)
+	}
+
+	if got := hostsEdit.Search(""); len(got) != 0 {
+		t.Errorf("Search(\"\") = %+v; want no results", got)
+	}
+}
+
+// 测试SubdomainsOf方法
+func TestSubdomainsOf(t *testing.T) {
+	hostsContent := "10.0.0.1 example.com API.Example.com notexample.com\n# 10.0.0.2 old.example.com\n10.0.0.3 a.b.example.com. api.example.com\n10.0.0.4 example.org\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	want := []Entry{
+		{IP: "10.0.0.1", Host: "example.com"},
+		{IP: "10.0.0.1", Host: "API.Example.com"},
+		{IP: "10.0.0.3", Host: "a.b.example.com."},
+	}
+	for _, domain := range []string{"example.com", "Example.COM."} {
+		if got := hostsEdit.SubdomainsOf(domain); !reflect.DeepEqual(got, want) {
+			t.Errorf("SubdomainsOf(%s) = %v; want %v", domain, got, want)
+		}
+	}
+	if got := hostsEdit.SubdomainsOf("le.com"); got != nil {
+		t.Errorf("SubdomainsOf(le.com) = %v; want none", got)
+	}
+}
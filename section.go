@@ -0,0 +1,182 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sections are blocks of lines between a "# BEGIN <name>" and a
+// "# END <name>" comment line, the markers tools use to delimit the entries
+// they manage.
+
+// AddSection appends an empty section with the given name, its BEGIN and
+// END markers, to the end of the file and saves it. The name must be a
+// single word without "#". It is an error if the section already exists.
+func (h *HostsEdit) AddSection(name string) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	if err := checkSectionName(name); err != nil {
+		return err
+	}
+	_, _, err := h.findSection(name)
+	if err == nil {
+		return fmt.Errorf("section %q already exists", name)
+	}
+	if !errors.Is(err, ErrSectionNotFound) {
+		return err
+	}
+	h.Lines = append(h.Lines, sectionMarker("BEGIN", name), sectionMarker("END", name))
+	return h.saveToFile(operation{name: "AddSection"})
+}
+
+// GetSection returns a new HostsEdit that holds copies of the lines between
+// the markers of the named section. It is not linked to h or to a file:
+// its changes are not reflected in h, and it can only be saved with SaveAs.
+// If the section does not exist, an error wrapping ErrSectionNotFound is
+// returned.
+func (h *HostsEdit) GetSection(name string) (*HostsEdit, error) {
+	begin, end, err := h.findSection(name)
+	if err != nil {
+		return nil, err
+	}
+	section := &HostsEdit{
+		Lines:        cloneLines(h.Lines[begin+1 : end]),
+		isParse:      h.isParse,
+		eol:          h.eol,
+		insertPolicy: h.insertPolicy,
+		indent:       h.indent,
+		renderConfig: h.renderConfig,
+	}
+	return section, nil
+}
+
+// DeleteSection removes the markers of the named section and every line
+// between them, and saves the file. If the section does not exist, an error
+// wrapping ErrSectionNotFound is returned.
+func (h *HostsEdit) DeleteSection(name string) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	begin, end, err := h.findSection(name)
+	if err != nil {
+		return err
+	}
+	h.Lines = append(h.Lines[:begin:begin], h.Lines[end+1:]...)
+	return h.saveToFile(operation{name: "DeleteSection"})
+}
+
+// EditInSection adds or updates the specified host with the given IP
+// address like Edit, but only within the named section, which is added at
+// the end of the file if it does not exist. Lines outside the section are
+// not changed, even if they map the same host; such a line takes precedence
+// if it comes before the section. New lines are placed at the end of the
+// section.
+func (h *HostsEdit) EditInSection(section, host, ip string) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	if h.lowercaseHosts {
+		host = lowerASCII(host)
+	}
+	prevIP, _ := h.Get(host)
+	changed, err := h.setInSection(section, host, ip)
+	if err != nil || !changed {
+		return err
+	}
+	h.recordHistory(OpSet, host, ip, prevIP)
+	return h.saveToFile(operation{name: "EditInSection", host: host, ip: ip})
+}
+
+// setInSection maps host to ip in memory within the named section, adding
+// the section if needed, and reports whether anything changed.
+func (h *HostsEdit) setInSection(section, host, ip string) (bool, error) {
+	if strings.TrimSpace(host) == "" || strings.TrimSpace(ip) == "" {
+		return false, errors.New("host or ip cannot be empty")
+	}
+	if err := checkSectionName(section); err != nil {
+		return false, err
+	}
+
+	begin, end, err := h.findSection(section)
+	if errors.Is(err, ErrSectionNotFound) {
+		h.Lines = append(h.Lines, sectionMarker("BEGIN", section), sectionMarker("END", section))
+		begin, end = len(h.Lines)-2, len(h.Lines)-1
+	} else if err != nil {
+		return false, err
+	}
+
+	// 只在区段内的行上执行set，新行追加到区段末尾
+	lines, policy := h.Lines, h.insertPolicy
+	h.Lines = append([]*Line(nil), lines[begin+1:end]...)
+	h.insertPolicy = InsertAppend
+	changed := h.set(host, ip)
+	inner := h.Lines
+	h.Lines, h.insertPolicy = lines, policy
+	if !changed {
+		return false, nil
+	}
+
+	h.Lines = append(append(append([]*Line(nil), lines[:begin+1]...), inner...), lines[end:]...)
+	return true, nil
+}
+
+// findSection returns the indexes of the BEGIN and END markers of the
+// named section. A BEGIN marker without an END marker is a *ParseError
+// wrapping ErrMalformedLine.
+func (h *HostsEdit) findSection(name string) (begin, end int, err error) {
+	begin = -1
+	for i, line := range h.Lines {
+		if begin < 0 && isSectionMarker(line, "BEGIN", name) {
+			begin = i
+		} else if begin >= 0 && isSectionMarker(line, "END", name) {
+			return begin, i, nil
+		}
+	}
+	if begin < 0 {
+		return 0, 0, fmt.Errorf("%w: %q", ErrSectionNotFound, name)
+	}
+	// 只有开始标记，没有结束标记
+	line := h.Lines[begin]
+	return 0, 0, &ParseError{Line: line.num, Text: line.UndefinedRowsRawStr, Err: ErrMalformedLine}
+}
+
+// checkSectionName returns an error if name cannot be used in a marker.
+func checkSectionName(name string) error {
+	if name == "" || strings.ContainsAny(name, "# \t\r\n") {
+		return errors.New("section name must be a single word without '#'")
+	}
+	return nil
+}
+
+// sectionMarker returns the BEGIN or END marker line of a section.
+func sectionMarker(kind, name string) *Line {
+	return &Line{IsComment: true, UndefinedRowsRawStr: kind + " " + name}
+}
+
+// isSectionMarker reports whether line is the BEGIN or END marker of the
+// named section.
+func isSectionMarker(line *Line, kind, name string) bool {
+	if !isPlainComment(line) {
+		return false
+	}
+	fields := strings.Fields(line.UndefinedRowsRawStr)
+	return len(fields) == 2 && fields[0] == kind && fields[1] == name
+}
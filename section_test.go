@@ -0,0 +1,127 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+// 测试AddSection、GetSection和DeleteSection方法
+func TestSections(t *testing.T) {
+	hostsContent := "127.0.0.1 localhost\n# BEGIN docker\n10.0.0.1 web.docker\n# END docker\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	err = hostsEdit.AddSection("myapp")
+	if err != nil {
+		t.Fatalf("AddSection(myapp) error = %v", err)
+	}
+	want := hostsContent + "# BEGIN myapp\n# END myapp\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("AddSection() wrote %q; want %q", contentBytes, want)
+	}
+	if err = hostsEdit.AddSection("docker"); err == nil {
+		t.Errorf("AddSection(docker) succeeded for an existing section")
+	}
+	if err = hostsEdit.AddSection("my app"); err == nil {
+		t.Errorf("AddSection(my app) succeeded for an invalid name")
+	}
+
+	section, err := hostsEdit.GetSection("docker")
+	if err != nil {
+		t.Fatalf("GetSection(docker) error = %v", err)
+	}
+	if len(section.Lines) != 1 || !section.Exists("web.docker") {
+		t.Errorf("GetSection(docker) lines = %d; want only web.docker", len(section.Lines))
+	}
+	if _, err = hostsEdit.GetSection("missing"); !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("GetSection(missing) error = %v; want ErrSectionNotFound", err)
+	}
+
+	err = hostsEdit.DeleteSection("docker")
+	if err != nil {
+		t.Fatalf("DeleteSection(docker) error = %v", err)
+	}
+	want = "127.0.0.1 localhost\n# BEGIN myapp\n# END myapp\n"
+	contentBytes, _ = os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("DeleteSection() wrote %q; want %q", contentBytes, want)
+	}
+}
+
+// 测试缺少结束标记的区段
+func TestSectionWithoutEnd(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n# BEGIN myapp\n10.0.0.1 a.test\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	_, err = hostsEdit.GetSection("myapp")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Line != 2 || !errors.Is(err, ErrMalformedLine) {
+		t.Errorf("GetSection(myapp) error = %v; want malformed line 2", err)
+	}
+	if err = hostsEdit.AddSection("myapp"); err == nil {
+		t.Errorf("AddSection(myapp) succeeded with an unclosed section")
+	}
+}
+
+// 测试EditInSection方法
+func TestEditInSection(t *testing.T) {
+	hostsContent := "10.0.0.1 shared.test\n# BEGIN myapp\n10.0.0.2 api.test\n# END myapp\n127.0.0.1 localhost\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	// 区段外的同名主机不受影响
+	err = hostsEdit.EditInSection("myapp", "shared.test", "10.0.0.9")
+	if err != nil {
+		t.Fatalf("EditInSection(myapp, shared.test) error = %v", err)
+	}
+	err = hostsEdit.EditInSection("myapp", "api.test", "10.0.0.3")
+	if err != nil {
+		t.Fatalf("EditInSection(myapp, api.test) error = %v", err)
+	}
+	want := "10.0.0.1 shared.test\n# BEGIN myapp\n10.0.0.3 api.test\n10.0.0.9 shared.test\n# END myapp\n127.0.0.1 localhost\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("EditInSection() wrote %q; want %q", contentBytes, want)
+	}
+
+	// 区段不存在时创建
+	err = hostsEdit.EditInSection("other", "o.test", "10.0.1.1")
+	if err != nil {
+		t.Fatalf("EditInSection(other, o.test) error = %v", err)
+	}
+	want += "# BEGIN other\n10.0.1.1 o.test\n# END other\n"
+	contentBytes, _ = os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("EditInSection() wrote %q; want %q", contentBytes, want)
+	}
+}
@@ -0,0 +1,74 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import "errors"
+
+// Snapshot is a copy of the lines of a HostsEdit, taken by Snapshot and put
+// back by RestoreSnapshot. It does not change when the HostsEdit does, and
+// it can be restored any number of times.
+type Snapshot struct {
+	lines []*Line
+	taken bool
+}
+
+// Snapshot returns a copy of the current lines, for undoing later changes
+// in memory with RestoreSnapshot. Nothing is read from or written to disk.
+func (h *HostsEdit) Snapshot() Snapshot {
+	return Snapshot{lines: cloneLines(h.Lines), taken: true}
+}
+
+// RestoreSnapshot replaces the lines with a copy of those in s. The file is
+// not written; the next save rewrites it as a whole with the restored lines.
+// It is an error to restore the zero Snapshot.
+func (h *HostsEdit) RestoreSnapshot(s Snapshot) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	if !s.taken {
+		return errors.New("snapshot was not taken with Snapshot")
+	}
+	h.Lines = cloneLines(s.lines)
+	// 恢复后的行与磁盘上的文件无关，不能走追加的方式
+	h.synced, h.syncedSize = nil, 0
+	h.clearHistory()
+	return nil
+}
+
+// ReadOnlySnapshot returns a new HostsEdit that holds a copy of the current
+// lines and is read-only, as with WithReadOnly, so that every method that
+// changes it returns ErrReadOnly. It is not linked to h or to a file: its
+// FilePath is empty and later changes of h are not reflected in it. It is
+// meant to be kept as a reference, for example to compute h.Diff(snapshot)
+// or snapshot.Diff(h) later.
+func (h *HostsEdit) ReadOnlySnapshot() *HostsEdit {
+	return &HostsEdit{
+		Lines:          cloneLines(h.Lines),
+		isParse:        h.isParse,
+		preserve:       h.preserve,
+		bom:            h.bom,
+		encodingSet:    h.encodingSet,
+		eol:            h.eol,
+		insertPolicy:   h.insertPolicy,
+		indent:         h.indent,
+		header:         h.header,
+		lowercaseHosts: h.lowercaseHosts,
+		readOnly:       true,
+		now:            h.now,
+		renderConfig:   h.renderConfig,
+	}
+}
@@ -0,0 +1,97 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+// 测试Snapshot和RestoreSnapshot
+func TestSnapshot(t *testing.T) {
+	hostsContent := "127.0.0.1 localhost\n10.0.0.1 a.test b.test\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false, WithInsertPolicy(InsertAppend))
+	snap := hostsEdit.Snapshot()
+
+	hostsEdit.Edit("a.test", "10.0.0.2")
+	hostsEdit.Edit("c.test", "10.0.0.3")
+	hostsEdit.Lines[0].AddHost("extra.test")
+
+	if err := hostsEdit.RestoreSnapshot(snap); err != nil {
+		t.Fatalf("RestoreSnapshot() error = %v", err)
+	}
+	if got := string(hostsEdit.render()); got != hostsContent {
+		t.Errorf("render() after RestoreSnapshot() = %q; want %q", got, hostsContent)
+	}
+	if err := hostsEdit.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != hostsContent {
+		t.Errorf("Save() after RestoreSnapshot() wrote %q; want %q", contentBytes, hostsContent)
+	}
+
+	// 快照可以多次恢复，恢复后的修改不影响快照
+	hostsEdit.Edit("b.test", "10.0.0.4")
+	hostsEdit.RestoreSnapshot(snap)
+	if got := string(hostsEdit.render()); got != hostsContent {
+		t.Errorf("render() after second RestoreSnapshot() = %q; want %q", got, hostsContent)
+	}
+
+	if err := hostsEdit.RestoreSnapshot(Snapshot{}); err == nil {
+		t.Errorf("RestoreSnapshot(Snapshot{}) succeeded; want error")
+	}
+}
+
+// 测试ReadOnlySnapshot方法
+func TestReadOnlySnapshot(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n10.0.0.1 a.test\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	snap := hostsEdit.ReadOnlySnapshot()
+	if snap.FilePath != "" {
+		t.Errorf("ReadOnlySnapshot().FilePath = %q; want empty", snap.FilePath)
+	}
+
+	hostsEdit.Edit("a.test", "10.0.0.2")
+	hostsEdit.Lines[0].AddHost("extra.test")
+	if ip, _ := snap.Get("a.test"); ip != "10.0.0.1" || snap.Exists("extra.test") {
+		t.Errorf("snapshot changed with the original: Get(a.test) = %q", ip)
+	}
+	d := snap.Diff(hostsEdit)
+	if len(d.Changed) != 1 || d.Changed[0].Host != "a.test" || len(d.Added) != 1 {
+		t.Errorf("Diff() = %+v; want a.test changed and extra.test added", d)
+	}
+
+	if err := snap.Edit("b.test", "10.0.0.3"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Edit() on snapshot error = %v; want ErrReadOnly", err)
+	}
+	if err := snap.Save(); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Save() on snapshot error = %v; want ErrReadOnly", err)
+	}
+}
@@ -0,0 +1,90 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// Store is where a hosts file is loaded from and saved to, for instances
+// created with NewWithStore. Instances created with New use the file at
+// FilePath directly.
+type Store interface {
+	// Load returns the content of the hosts file.
+	Load() (io.ReadCloser, error)
+	// Save replaces the content of the hosts file.
+	Save(content []byte) error
+}
+
+// FileStore is a Store for the file at Path.
+type FileStore struct {
+	Path string
+}
+
+// Load opens the file.
+func (s FileStore) Load() (io.ReadCloser, error) {
+	return os.Open(s.Path)
+}
+
+// Save writes content to the file, creating it with mode 0644 if needed.
+func (s FileStore) Save(content []byte) error {
+	return os.WriteFile(s.Path, content, 0o644)
+}
+
+// MemStore is a Store that keeps the hosts file in memory, for tests.
+type MemStore struct {
+	Content []byte
+}
+
+// Load returns a reader for Content.
+func (s *MemStore) Load() (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(s.Content)), nil
+}
+
+// Save sets Content to a copy of content.
+func (s *MemStore) Save(content []byte) error {
+	s.Content = append([]byte(nil), content...)
+	return nil
+}
+
+// NewWithStore loads the hosts file from store and returns a HostsEdit
+// instance that saves to it. isParse and opts work as for New. FilePath is
+// left empty.
+func NewWithStore(store Store, isParse bool, opts ...Option) (*HostsEdit, error) {
+	h := &HostsEdit{isParse: isParse, eol: "\n", store: store}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	rc, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	changed, err := h.load(rc)
+	if err != nil {
+		return nil, err
+	}
+
+	if !changed {
+		h.markSynced(0)
+	}
+	return h, nil
+}
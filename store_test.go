@@ -0,0 +1,77 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+// 测试NewWithStore使用内存存储
+func TestNewWithStore(t *testing.T) {
+	store := &MemStore{Content: []byte("127.0.0.1 localhost\n")}
+	hostsEdit, err := NewWithStore(store, true, WithInsertPolicy(InsertAppend))
+	if err != nil {
+		t.Fatalf("NewWithStore() error = %v", err)
+	}
+
+	err = hostsEdit.Edit("myapp.local", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Edit(myapp.local, 10.0.0.1) failed with error: %v", err)
+	}
+	want := "127.0.0.1 localhost\n10.0.0.1 myapp.local\n"
+	if string(store.Content) != want {
+		t.Errorf("store content = %q; want %q", store.Content, want)
+	}
+
+	err = hostsEdit.SaveWithContext(context.Background())
+	if err != nil {
+		t.Fatalf("SaveWithContext() error = %v", err)
+	}
+	if string(store.Content) != want {
+		t.Errorf("store content after SaveWithContext = %q; want %q", store.Content, want)
+	}
+
+	store.Content = []byte("127.0.0.1 localhost\n127.0.0.2 localhost\n")
+	if _, err := NewWithStore(store, true); err == nil {
+		t.Errorf("NewWithStore() with duplicate hosts in strict mode should fail")
+	}
+}
+
+// 测试FileStore与New的行为一致
+func TestFileStore(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, err := NewWithStore(FileStore{Path: filePath}, false)
+	if err != nil {
+		t.Fatalf("NewWithStore() error = %v", err)
+	}
+	err = hostsEdit.Edit("myapp.local", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Edit(myapp.local, 10.0.0.1) failed with error: %v", err)
+	}
+
+	updatedHostsEdit, _ := New(filePath, false)
+	if ip, _ := updatedHostsEdit.Get("myapp.local"); ip != "10.0.0.1" {
+		t.Errorf("Get(myapp.local) = %v; want 10.0.0.1", ip)
+	}
+}
@@ -0,0 +1,121 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"bufio"
+	"io"
+	"os"
+)
+
+// Stream parses the hosts file at path one line at a time and calls fn with
+// the line number and the line, for every line New would load without
+// options. Only the current line is kept in memory, so files too large to
+// load with New can be processed. If fn returns an error, Stream stops and
+// returns it.
+func Stream(path string, fn func(ln int, l *Line) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	r, _ := stripBOM(file)
+	return scanEntries(r, false, func(line *Line) error {
+		return fn(line.num, line)
+	})
+}
+
+// StreamTransform is like Stream, but writes the line returned by fn to w,
+// rendered as saving would render it. If fn returns a nil line, nothing is
+// written for it. If fn returns an error, StreamTransform stops and returns
+// it; part of the output may already have been written to w in that case.
+func StreamTransform(path string, w io.Writer, fn func(ln int, l *Line) (*Line, error)) error {
+	bw := bufio.NewWriter(w)
+	err := Stream(path, func(ln int, l *Line) error {
+		line, err := fn(ln, l)
+		if err != nil || line == nil {
+			return err
+		}
+		bw.WriteString(FormatLine(line))
+		_, err = bw.WriteString("\n")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// HostsIterator reads the lines of a hosts file one at a time. It is
+// returned by NewStreaming.
+type HostsIterator struct {
+	file    *os.File
+	scanner *lineScanner
+	err     error
+}
+
+// NewStreaming opens the hosts file at filePath for reading one line at a
+// time with Next, for every line New would load without options. Only the
+// current line is kept in memory. An error opening the file is returned by
+// the first call to Next. The iterator must be closed with Close.
+func NewStreaming(filePath string) *HostsIterator {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return &HostsIterator{err: err}
+	}
+	r, _ := stripBOM(file)
+	return &HostsIterator{file: file, scanner: newLineScanner(r, false)}
+}
+
+// Next returns the next line of the file. It returns io.EOF after the last
+// line, and keeps returning the first error it met on later calls.
+func (it *HostsIterator) Next() (*Line, error) {
+	if it.err != nil {
+		return nil, it.err
+	}
+	line, ok := it.scanner.next()
+	if ok {
+		return line, nil
+	}
+	it.err = it.scanner.err()
+	if it.err == nil {
+		it.err = io.EOF
+	}
+	return nil, it.err
+}
+
+// Line returns the 1-based line number of the line last returned by Next.
+func (it *HostsIterator) Line() int {
+	if it.scanner == nil {
+		return 0
+	}
+	return it.scanner.num
+}
+
+// Close closes the file. Next returns os.ErrClosed after Close.
+func (it *HostsIterator) Close() error {
+	if it.file == nil {
+		return nil
+	}
+	err := it.file.Close()
+	it.file = nil
+	if it.err == nil {
+		it.err = os.ErrClosed
+	}
+	return err
+}
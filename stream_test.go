@@ -0,0 +1,137 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// 测试Stream函数
+func TestStream(t *testing.T) {
+	hostsContent := "127.0.0.1 localhost\n\n# comment\n0.0.0.0 ads.test tracker.test\n::1 ipv6host\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	var nums []int
+	err = Stream(filePath, func(ln int, l *Line) error {
+		nums = append(nums, ln)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if len(nums) != 4 || nums[0] != 1 || nums[1] != 3 || nums[3] != 5 {
+		t.Errorf("Stream() line numbers = %v; want [1 3 4 5]", nums)
+	}
+
+	// 回调返回错误时中止
+	errStop := errors.New("stop")
+	calls := 0
+	err = Stream(filePath, func(ln int, l *Line) error {
+		calls++
+		if l.HasHost("ads.test") {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) || calls != 3 {
+		t.Errorf("Stream() = %v after %d calls; want %v after 3 calls", err, calls, errStop)
+	}
+}
+
+// 测试StreamTransform函数
+func TestStreamTransform(t *testing.T) {
+	hostsContent := "127.0.0.1 localhost\n0.0.0.0 ads.test tracker.test\n# comment\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	var buf bytes.Buffer
+	err = StreamTransform(filePath, &buf, func(ln int, l *Line) (*Line, error) {
+		if l.IP == "0.0.0.0" {
+			l.RemoveHost("ads.test")
+		}
+		if l.IsComment {
+			return nil, nil
+		}
+		return l, nil
+	})
+	if err != nil {
+		t.Fatalf("StreamTransform() error = %v", err)
+	}
+	want := "127.0.0.1 localhost\n0.0.0.0 tracker.test\n"
+	if buf.String() != want {
+		t.Errorf("StreamTransform() wrote %q; want %q", buf.String(), want)
+	}
+}
+
+// 测试NewStreaming逐行读取
+func TestNewStreaming(t *testing.T) {
+	hostsContent := "\xef\xbb\xbf127.0.0.1 localhost\n\n# comment\n0.0.0.0 ads.test tracker.test"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	it := NewStreaming(filePath)
+	var got []string
+	var nums []int
+	for {
+		line, err := it.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		got = append(got, FormatLine(line))
+		nums = append(nums, it.Line())
+	}
+	want := []string{"127.0.0.1 localhost", "# comment", "0.0.0.0 ads.test tracker.test"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Next() lines = %q; want %q", got, want)
+	}
+	if !reflect.DeepEqual(nums, []int{1, 3, 4}) {
+		t.Errorf("Line() = %v; want [1 3 4]", nums)
+	}
+	if _, err := it.Next(); err != io.EOF {
+		t.Errorf("Next() after end error = %v; want io.EOF", err)
+	}
+	if err := it.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+
+	// 打开失败的错误由Next返回
+	it = NewStreaming(filePath + ".missing")
+	if _, err := it.Next(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Next() error = %v; want os.ErrNotExist", err)
+	}
+	if err := it.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import "fmt"
+
+// Transform calls fn for every host of every active entry, in file order,
+// and replaces the entry with the one fn returns, or removes it if fn
+// returns false. An entry can be given another host name, another IP
+// address, or both. All calls to fn are made before anything is changed.
+//
+// Every entry fn changes must have a valid IP address and a host name
+// accepted by ValidateHostname; otherwise an error is returned and nothing
+// is changed. With WithLowercaseHosts, new host names are written in
+// lowercase.
+//
+// Hosts that keep the IP address of their line stay on it, so comments and
+// the other lines of the file are kept as they are. Hosts given another
+// address are moved to new lines right after their line, one per address,
+// unless none of the hosts of the line keep its address, in which case the
+// line takes the address of its first host instead. Lines left without
+// hosts are removed. The file is saved once, only if an entry changed.
+func (h *HostsEdit) Transform(fn func(e Entry) (Entry, bool)) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+
+	results := make(map[*Line][]Entry)
+	changed := false
+	for _, line := range h.Lines {
+		if !isActive(line) {
+			continue
+		}
+		kept := []Entry{}
+		for _, host := range line.hosts {
+			e := Entry{IP: line.IP, Host: host}
+			r, keep := fn(e)
+			if !keep {
+				changed = true
+				continue
+			}
+			if r == e {
+				kept = append(kept, r)
+				continue
+			}
+			if !isAddr(r.IP) {
+				return fmt.Errorf("transform of %s: invalid ip %q", host, r.IP)
+			}
+			if err := ValidateHostname(r.Host); err != nil {
+				return fmt.Errorf("transform of %s: %w", host, err)
+			}
+			if h.lowercaseHosts {
+				r.Host = lowerASCII(r.Host)
+			}
+			kept = append(kept, r)
+			changed = true
+		}
+		results[line] = kept
+	}
+	if !changed {
+		return nil
+	}
+
+	var lines []*Line
+	for _, line := range h.Lines {
+		kept, ok := results[line]
+		if !ok {
+			lines = append(lines, line)
+			continue
+		}
+		if len(kept) == 0 {
+			continue
+		}
+		lines = append(lines, line)
+		lines = append(lines, transformLine(line, kept)...)
+	}
+	h.Lines = lines
+	return h.saveToFile(operation{name: "Transform"})
+}
+
+// transformLine gives line the hosts of kept that keep its IP address, or
+// the address and hosts of the first entry of kept if none does, and
+// returns new lines for the other entries, grouped by address.
+func transformLine(line *Line, kept []Entry) []*Line {
+	ip := line.IP
+	if !containsIP(kept, ip) {
+		ip = kept[0].IP
+	}
+
+	var hosts []string
+	var newLines []*Line
+	for _, e := range kept {
+		if sameIP(e.IP, ip) {
+			hosts = append(hosts, e.Host)
+			continue
+		}
+		var target *Line
+		for _, l := range newLines {
+			if sameIP(l.IP, e.IP) {
+				target = l
+				break
+			}
+		}
+		if target == nil {
+			target = NewLine(e.IP)
+			target.indent = line.indent
+			newLines = append(newLines, target)
+		}
+		target.AddHost(e.Host)
+	}
+
+	if ip != line.IP || !equalHosts(line.hosts, hosts) {
+		if ip != line.IP {
+			line.setIP(ip)
+		}
+		line.setHosts(hosts)
+		line.touch()
+	}
+	return newLines
+}
+
+// containsIP reports whether an entry of entries has the address ip.
+func containsIP(entries []Entry, ip string) bool {
+	for _, e := range entries {
+		if sameIP(e.IP, ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalHosts reports whether a and b hold the same hosts in the same order.
+func equalHosts(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
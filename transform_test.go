@@ -0,0 +1,92 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// 测试Transform方法
+func TestTransform(t *testing.T) {
+	hostsContent := "# dev hosts\n127.0.0.1 localhost\n10.0.0.1 db cache.example.com # shared\n10.0.0.2 web\n# 10.0.0.3 old\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	// 给没有域名的主机加上.internal后缀
+	err = hostsEdit.Transform(func(e Entry) (Entry, bool) {
+		if !strings.Contains(e.Host, ".") && e.Host != "localhost" {
+			e.Host += ".internal"
+		}
+		return e, true
+	})
+	if err != nil {
+		t.Fatalf("Transform() error = %v", err)
+	}
+	want := "# dev hosts\n127.0.0.1 localhost\n10.0.0.1 db.internal cache.example.com # shared\n10.0.0.2 web.internal\n# 10.0.0.3 old\n"
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+
+	// 修改IP的主机移到新的行，返回false的主机被删除
+	err = hostsEdit.Transform(func(e Entry) (Entry, bool) {
+		switch e.Host {
+		case "cache.example.com":
+			e.IP = "10.0.0.9"
+		case "web.internal":
+			return e, false
+		case "localhost":
+			e.IP = "::1"
+		}
+		return e, true
+	})
+	if err != nil {
+		t.Fatalf("Transform() error = %v", err)
+	}
+	want = "# dev hosts\n::1 localhost\n10.0.0.1 db.internal # shared\n10.0.0.9 cache.example.com\n# 10.0.0.3 old\n"
+	contentBytes, _ = os.ReadFile(filePath)
+	if string(contentBytes) != want {
+		t.Errorf("saved content = %q; want %q", contentBytes, want)
+	}
+
+	// 任何一个结果无效时不做修改
+	for _, bad := range []Entry{{IP: "10.0.0.300", Host: "db.internal"}, {IP: "10.0.0.1", Host: "bad_name.test"}} {
+		err = hostsEdit.Transform(func(e Entry) (Entry, bool) {
+			if e.Host == "db.internal" {
+				return bad, true
+			}
+			return Entry{IP: e.IP, Host: "renamed.test"}, true
+		})
+		if err == nil {
+			t.Errorf("Transform() returning %v succeeded", bad)
+		}
+	}
+	if !errors.Is(err, ErrInvalidHostname) {
+		t.Errorf("Transform() error = %v; want ErrInvalidHostname", err)
+	}
+	contentBytes, _ = os.ReadFile(filePath)
+	if string(contentBytes) != want || !hostsEdit.Exists("localhost") {
+		t.Errorf("Transform() changed the file although an entry was invalid: %q", contentBytes)
+	}
+}
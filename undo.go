@@ -0,0 +1,223 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import "net/netip"
+
+// WithUndo keeps a history of the last depth operations done with Edit,
+// Delete and EditBatch, so that Undo and Redo can reverse and repeat them.
+// Without it, or with a depth of 0 or less, no history is kept.
+func WithUndo(depth int) Option {
+	return func(h *HostsEdit) {
+		h.undoDepth = depth
+	}
+}
+
+// undoStep records how one operation changed the lines: the lines from
+// start that it replaced, the lines that replaced them, and the content
+// before and after of the lines it may have changed, added or removed.
+type undoStep struct {
+	start               int
+	before, after       []*Line
+	beforeLen, afterLen int
+	lines               []*Line
+	old, new            []*Line // clones of lines before and after
+
+	snapshot []*Line // the whole list before the operation, until endStep
+}
+
+// Undo reverses the last operation recorded with WithUndo, restoring the
+// lines it changed exactly as they were, with their position, comments and
+// formatting. The file is not written; the next save rewrites it as a whole,
+// so an operation can be undone after it was saved. Undo returns
+// ErrNothingToUndo if there is no operation to reverse, and
+// ErrHistoryChanged, clearing the history, if the lines involved were
+// changed since in a way the history does not record. The history is also
+// cleared when the lines are loaded again, as by RestoreFrom or Import.
+func (h *HostsEdit) Undo() error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	if len(h.undo) == 0 {
+		return ErrNothingToUndo
+	}
+	s := h.undo[len(h.undo)-1]
+	if !s.matches(h.Lines, s.after, s.afterLen, s.new) {
+		h.clearHistory()
+		return ErrHistoryChanged
+	}
+	h.undo = h.undo[:len(h.undo)-1]
+	h.Lines = s.replace(h.Lines, s.after, s.before, s.old)
+	h.redo = append(h.redo, s)
+	h.synced, h.syncedSize = nil, 0
+	return nil
+}
+
+// Redo repeats the last operation reversed by Undo, in memory like Undo.
+// Any new operation recorded after Undo discards the operations that could
+// be redone. Redo returns ErrNothingToRedo if there is none, and
+// ErrHistoryChanged as Undo does.
+func (h *HostsEdit) Redo() error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	if len(h.redo) == 0 {
+		return ErrNothingToRedo
+	}
+	s := h.redo[len(h.redo)-1]
+	if !s.matches(h.Lines, s.before, s.beforeLen, s.old) {
+		h.clearHistory()
+		return ErrHistoryChanged
+	}
+	h.redo = h.redo[:len(h.redo)-1]
+	h.Lines = s.replace(h.Lines, s.before, s.after, s.new)
+	h.undo = append(h.undo, s)
+	h.synced, h.syncedSize = nil, 0
+	return nil
+}
+
+// beginStep starts recording an operation that may change the lines for
+// which match returns true, besides adding and removing lines. It returns
+// nil without WithUndo.
+func (h *HostsEdit) beginStep(match func(line *Line) bool) *undoStep {
+	if h.undoDepth <= 0 {
+		return nil
+	}
+	s := &undoStep{snapshot: append([]*Line(nil), h.Lines...)}
+	for _, line := range h.Lines {
+		if match(line) {
+			s.lines = append(s.lines, line)
+			s.old = append(s.old, line.clone())
+		}
+	}
+	return s
+}
+
+// endStep completes s once the operation is done and adds it to the
+// history. It does nothing if s is nil.
+func (h *HostsEdit) endStep(s *undoStep) {
+	if s == nil {
+		return
+	}
+	before, after := s.snapshot, h.Lines
+	s.snapshot = nil
+	start := 0
+	for start < len(before) && start < len(after) && before[start] == after[start] {
+		start++
+	}
+	end := 0
+	for end < len(before)-start && end < len(after)-start &&
+		before[len(before)-1-end] == after[len(after)-1-end] {
+		end++
+	}
+	s.start = start
+	s.before = append([]*Line(nil), before[start:len(before)-end]...)
+	s.after = append([]*Line(nil), after[start:len(after)-end]...)
+	s.beforeLen, s.afterLen = len(before), len(after)
+	for _, line := range s.lines {
+		s.new = append(s.new, line.clone())
+	}
+	// 增加或删除的其他行没有被修改，前后内容相同
+	known := make(map[*Line]bool, len(s.lines))
+	for _, line := range s.lines {
+		known[line] = true
+	}
+	for _, line := range append(append([]*Line(nil), s.before...), s.after...) {
+		if !known[line] {
+			known[line] = true
+			s.lines = append(s.lines, line)
+			s.old = append(s.old, line.clone())
+			s.new = append(s.new, line.clone())
+		}
+	}
+
+	h.undo = append(h.undo, s)
+	if len(h.undo) > h.undoDepth {
+		h.undo = append(h.undo[:0:0], h.undo[len(h.undo)-h.undoDepth:]...)
+	}
+	h.redo = nil
+}
+
+// clearHistory drops every operation recorded for Undo and Redo.
+func (h *HostsEdit) clearHistory() {
+	h.undo, h.redo = nil, nil
+}
+
+// matches reports whether lines are in the state the step leaves them in,
+// that is with n lines, mid at the start of the step and the changed lines
+// holding the content of states.
+func (s *undoStep) matches(lines, mid []*Line, n int, states []*Line) bool {
+	if len(lines) != n || s.start+len(mid) > n {
+		return false
+	}
+	for i, line := range mid {
+		if lines[s.start+i] != line {
+			return false
+		}
+	}
+	for i, line := range s.lines {
+		if !sameContent(line, states[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// replace returns lines with from, at the start of the step, replaced by
+// to, after restoring the content of the changed lines from states.
+func (s *undoStep) replace(lines, from, to, states []*Line) []*Line {
+	for i, line := range s.lines {
+		*line = *states[i].clone()
+	}
+	result := make([]*Line, 0, len(lines)-len(from)+len(to))
+	result = append(result, lines[:s.start]...)
+	result = append(result, to...)
+	return append(result, lines[s.start+len(from):]...)
+}
+
+// sameContent reports whether a and b render to the same text.
+func sameContent(a, b *Line) bool {
+	return a.IsComment == b.IsComment && a.UndefinedRowsRawStr == b.UndefinedRowsRawStr &&
+		a.IP == b.IP && equalHosts(a.hosts, b.hosts) && equalHosts(a.Extra, b.Extra) &&
+		a.InlineComment == b.InlineComment && a.indent == b.indent
+}
+
+// hostOrAddr returns a match function for beginStep that selects the lines
+// listing one of hosts, enabled or not, and the active lines using one of
+// ips.
+func hostOrAddr(hosts []string, ips []string) func(line *Line) bool {
+	addrs := make([]netip.Addr, len(ips))
+	for i, ip := range ips {
+		addrs[i], _ = netip.ParseAddr(ip)
+	}
+	return func(line *Line) bool {
+		for _, host := range hosts {
+			if line.HasHost(host) {
+				return true
+			}
+		}
+		if !isActive(line) {
+			return false
+		}
+		for i, ip := range ips {
+			if line.hasAddr(ip, addrs[i]) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,116 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+// 测试Undo和Redo
+func TestUndoRedo(t *testing.T) {
+	hostsContent := "# comment\n127.0.0.1   localhost\n\n10.0.0.1\ta.test b.test  # inline\n10.0.0.2 c.test\n"
+	filePath, err := createTestHostsFile(hostsContent)
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false, WithPreserveFormatting(), WithUndo(10))
+	if err := hostsEdit.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Fatalf("Undo() error = %v; want ErrNothingToUndo", err)
+	}
+
+	hostsEdit.Edit("a.test", "10.0.0.9")
+	hostsEdit.Delete("c.test")
+	hostsEdit.Edit("new.test", "10.0.0.1")
+	edited, _ := os.ReadFile(filePath)
+
+	// 撤销后行的位置、注释和格式都恢复原样
+	for i := 0; i < 3; i++ {
+		if err := hostsEdit.Undo(); err != nil {
+			t.Fatalf("Undo() #%d error = %v", i+1, err)
+		}
+	}
+	if err := hostsEdit.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("fourth Undo() error = %v; want ErrNothingToUndo", err)
+	}
+	hostsEdit.Save()
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != hostsContent {
+		t.Errorf("Save() after Undo() wrote %q; want %q", contentBytes, hostsContent)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := hostsEdit.Redo(); err != nil {
+			t.Fatalf("Redo() #%d error = %v", i+1, err)
+		}
+	}
+	if err := hostsEdit.Redo(); !errors.Is(err, ErrNothingToRedo) {
+		t.Errorf("fourth Redo() error = %v; want ErrNothingToRedo", err)
+	}
+	hostsEdit.Save()
+	contentBytes, _ = os.ReadFile(filePath)
+	if string(contentBytes) != string(edited) {
+		t.Errorf("Save() after Redo() wrote %q; want %q", contentBytes, edited)
+	}
+
+	// 新的操作清除可以重做的操作
+	hostsEdit.Undo()
+	hostsEdit.Edit("other.test", "10.0.0.3")
+	if err := hostsEdit.Redo(); !errors.Is(err, ErrNothingToRedo) {
+		t.Errorf("Redo() after Edit() error = %v; want ErrNothingToRedo", err)
+	}
+
+	// 历史之外修改了相关的行
+	hostsEdit.ReplaceIP("10.0.0.3", "10.0.0.4")
+	if err := hostsEdit.Undo(); !errors.Is(err, ErrHistoryChanged) {
+		t.Errorf("Undo() after ReplaceIP() error = %v; want ErrHistoryChanged", err)
+	}
+	if err := hostsEdit.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Undo() after ErrHistoryChanged error = %v; want ErrNothingToUndo", err)
+	}
+}
+
+// 测试历史深度
+func TestUndoDepth(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false, WithUndo(2))
+	hostsEdit.Edit("a.test", "10.0.0.1")
+	hostsEdit.Edit("b.test", "10.0.0.2")
+	hostsEdit.Edit("c.test", "10.0.0.3")
+	undone := 0
+	for hostsEdit.Undo() == nil {
+		undone++
+	}
+	if undone != 2 || !hostsEdit.Exists("a.test") || hostsEdit.Exists("b.test") {
+		t.Errorf("undid %d operations; want the last 2", undone)
+	}
+
+	// 没有WithUndo时不记录历史
+	hostsEdit, _ = New(filePath, false)
+	hostsEdit.Edit("d.test", "10.0.0.4")
+	if err := hostsEdit.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Undo() without WithUndo error = %v; want ErrNothingToUndo", err)
+	}
+}
@@ -0,0 +1,121 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultHTTPTimeout is the timeout of NewFromURL unless WithHTTPTimeout is
+// used.
+const defaultHTTPTimeout = 30 * time.Second
+
+// WithHTTPTimeout sets the timeout of the whole request made by NewFromURL.
+// The default is 30 seconds.
+func WithHTTPTimeout(d time.Duration) Option {
+	return func(h *HostsEdit) {
+		h.httpTimeout = d
+	}
+}
+
+// NewFromReader parses hosts file content read from r and returns a
+// HostsEdit instance that is not backed by a file. isParse and opts work as
+// for New. FilePath is empty, so SaveAs must be used to persist it.
+func NewFromReader(r io.Reader, isParse bool, opts ...Option) (*HostsEdit, error) {
+	h := &HostsEdit{isParse: isParse, eol: "\n"}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	_, err := h.load(r)
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// NewFromURL downloads a hosts file, such as a published blocklist, and
+// parses it leniently, as New does without isParse. The response must have
+// status 200 and, if it has a Content-Type, be text/plain or
+// application/octet-stream in UTF-8 or ASCII, so an HTML error page is not
+// mistaken for a hosts file. FilePath is empty, so SaveAs must be used to
+// persist it.
+func NewFromURL(rawURL string, opts ...Option) (*HostsEdit, error) {
+	h := &HostsEdit{eol: "\n"}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	body, err := h.fetch(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	_, err = h.load(body)
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// fetch returns the body of a hosts file downloaded from rawURL.
+func (h *HostsEdit) fetch(rawURL string) (io.ReadCloser, error) {
+	timeout := h.httpTimeout
+	if timeout == 0 {
+		timeout = defaultHTTPTimeout
+	}
+	client := &http.Client{Timeout: timeout}
+
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("get %s: %s", rawURL, resp.Status)
+	}
+	if err := checkContentType(resp.Header.Get("Content-Type")); err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("get %s: %w", rawURL, err)
+	}
+	return resp.Body, nil
+}
+
+// checkContentType returns an error if a response with the Content-Type ct
+// cannot be a hosts file.
+func checkContentType(ct string) error {
+	if ct == "" {
+		return nil
+	}
+	mediaType, params, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return fmt.Errorf("invalid content type %q", ct)
+	}
+	if mediaType != "text/plain" && mediaType != "application/octet-stream" {
+		return fmt.Errorf("unexpected content type %q", mediaType)
+	}
+	if charset := strings.ToLower(params["charset"]); charset != "" && charset != "utf-8" && charset != "us-ascii" {
+		return fmt.Errorf("unsupported charset %q", charset)
+	}
+	return nil
+}
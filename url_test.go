@@ -0,0 +1,76 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 测试NewFromURL和SaveAs
+func TestNewFromURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/hosts":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Write([]byte("# blocklist\n0.0.0.0 ads.test\n0.0.0.0 tracker.test\n"))
+		case "/html":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html>rate limited</html>"))
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	hostsEdit, err := NewFromURL(server.URL + "/hosts")
+	if err != nil {
+		t.Fatalf("NewFromURL() error = %v", err)
+	}
+	if hostsEdit.FilePath != "" || !hostsEdit.Exists("tracker.test") {
+		t.Errorf("NewFromURL() = %q with tracker.test %v; want no path and tracker.test", hostsEdit.FilePath, hostsEdit.Exists("tracker.test"))
+	}
+
+	// 没有文件路径时必须使用SaveAs
+	if err := hostsEdit.Edit("ads.test", "127.0.0.1"); err == nil || !strings.Contains(err.Error(), "SaveAs") {
+		t.Errorf("Edit() without a file path error = %v; want a hint to use SaveAs", err)
+	}
+	filePath := filepath.Join(t.TempDir(), "hosts")
+	if err := hostsEdit.SaveAs(filePath); err != nil {
+		t.Fatalf("SaveAs() error = %v", err)
+	}
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != "# blocklist\n127.0.0.1 ads.test\n0.0.0.0 tracker.test\n" {
+		t.Errorf("SaveAs() wrote %q", contentBytes)
+	}
+
+	for _, path := range []string{"/html", "/missing"} {
+		if _, err := NewFromURL(server.URL + path); err == nil {
+			t.Errorf("NewFromURL(%s) should fail", path)
+		}
+	}
+	if _, err := NewFromURL(server.URL+"/slow", WithHTTPTimeout(50*time.Millisecond)); err == nil {
+		t.Errorf("NewFromURL() should time out")
+	}
+}
@@ -0,0 +1,78 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import "fmt"
+
+// ValidateHostname checks that host is a valid host name by the rules of
+// RFC 1123, section 2.1: dot-separated labels of 1 to 63 letters, digits and
+// hyphens that do not start or end with a hyphen, 253 characters at most in
+// total. A single trailing dot is allowed. Underscores, which some tools
+// accept, are rejected. The error wraps ErrInvalidHostname.
+func ValidateHostname(host string) error {
+	name := host
+	if len(name) > 0 && name[len(name)-1] == '.' {
+		name = name[:len(name)-1]
+	}
+	if name == "" {
+		return fmt.Errorf("%w %q: empty", ErrInvalidHostname, host)
+	}
+	if len(name) > 253 {
+		return fmt.Errorf("%w %q: longer than 253 characters", ErrInvalidHostname, host)
+	}
+
+	start := 0
+	for i := 0; i <= len(name); i++ {
+		if i < len(name) && name[i] != '.' {
+			c := name[i]
+			if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-') {
+				return fmt.Errorf("%w %q: invalid character %q", ErrInvalidHostname, host, c)
+			}
+			continue
+		}
+		label := name[start:i]
+		switch {
+		case label == "":
+			return fmt.Errorf("%w %q: empty label", ErrInvalidHostname, host)
+		case len(label) > 63:
+			return fmt.Errorf("%w %q: label longer than 63 characters", ErrInvalidHostname, host)
+		case label[0] == '-' || label[len(label)-1] == '-':
+			return fmt.Errorf("%w %q: label starts or ends with a hyphen", ErrInvalidHostname, host)
+		}
+		start = i + 1
+	}
+	return nil
+}
+
+// validateHostnames checks the hosts of the active entries of lines with
+// ValidateHostname, for WithStrictHostnameValidation. It returns a
+// *ParseError for the first invalid host.
+func validateHostnames(lines []*Line) error {
+	for _, line := range lines {
+		if !isActive(line) {
+			continue
+		}
+		for i, host := range line.hosts {
+			if ValidateHostname(host) != nil {
+				err := &ParseError{Line: line.num, Text: host, Err: ErrInvalidHostname}
+				err.Column, err.Length = line.fieldSpan(i + 1)
+				return err
+			}
+		}
+	}
+	return nil
+}
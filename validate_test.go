@@ -0,0 +1,79 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// 测试ValidateHostname函数
+func TestValidateHostname(t *testing.T) {
+	tests := []struct {
+		host  string
+		valid bool
+	}{
+		{"localhost", true},
+		{"www.example.com", true},
+		{"example.com.", true},
+		{"3com.example", true},
+		{"a-b.test", true},
+		{strings.Repeat("a", 63) + ".test", true},
+		{"", false},
+		{".", false},
+		{"a..b", false},
+		{"-a.test", false},
+		{"a-.test", false},
+		{"under_score.test", false},
+		{"bücher.test", false},
+		{strings.Repeat("a", 64) + ".test", false},
+		{strings.Repeat("a.", 127) + "ab", false},
+	}
+	for _, tt := range tests {
+		err := ValidateHostname(tt.host)
+		if tt.valid && err != nil {
+			t.Errorf("ValidateHostname(%q) error = %v; want nil", tt.host, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidHostname) {
+			t.Errorf("ValidateHostname(%q) error = %v; want ErrInvalidHostname", tt.host, err)
+		}
+	}
+}
+
+// 测试WithStrictHostnameValidation选项
+func TestWithStrictHostnameValidation(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n# 10.0.0.1 off_line.test\n10.0.0.2 bad_host.test\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	// 默认和isParse都不检查主机名
+	if _, err = New(filePath, true); err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	_, err = New(filePath, false, WithStrictHostnameValidation())
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || !errors.Is(err, ErrInvalidHostname) {
+		t.Fatalf("New() error = %v; want *ParseError with ErrInvalidHostname", err)
+	}
+	if parseErr.Line != 3 || parseErr.Text != "bad_host.test" {
+		t.Errorf("New() error = %v; want bad_host.test on line 3", err)
+	}
+}
@@ -0,0 +1,145 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import "sync"
+
+// watchBuffer is the capacity of the channels returned by Watch.
+const watchBuffer = 64
+
+// ChangeEvent describes the change of the mapping of one host, sent on the
+// channels returned by Watch.
+type ChangeEvent struct {
+	Kind  ChangeOp
+	Host  string
+	OldIP string // "" for OpAdd
+	NewIP string // "" for OpDelete
+	Line  int    // index in Lines of the entry now used for Host, -1 for OpDelete
+}
+
+// watcher is a channel returned by Watch.
+type watcher struct {
+	mu     sync.Mutex // guards sends against closing
+	ch     chan ChangeEvent
+	closed bool
+}
+
+// Watch returns a channel that receives a ChangeEvent for every host whose
+// mapping was changed by a save, whichever method made the change: a bulk
+// operation sends one event per host it changed. Events are sent once the
+// save has succeeded, in the order OnChange reports them. The channel is
+// buffered and never blocks the methods that change the file: when it is
+// full, the oldest event is dropped to make room for the new one, so a
+// consumer that falls behind sees the latest changes. The returned cancel
+// function stops the events and closes the channel; it may be called more
+// than once and from any goroutine.
+func (h *HostsEdit) Watch() (<-chan ChangeEvent, func()) {
+	w := &watcher{ch: make(chan ChangeEvent, watchBuffer)}
+	h.subMu.Lock()
+	if !h.hasListenersLocked() {
+		h.notified, h.notifiedOrder = h.effective()
+	}
+	h.watchers = append(h.watchers, w)
+	h.subMu.Unlock()
+
+	cancel := func() {
+		h.subMu.Lock()
+		for i, v := range h.watchers {
+			if v == w {
+				h.watchers = append(h.watchers[:i:i], h.watchers[i+1:]...)
+				break
+			}
+		}
+		h.subMu.Unlock()
+
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if !w.closed {
+			w.closed = true
+			close(w.ch)
+		}
+	}
+	return w.ch, cancel
+}
+
+// sendChangeEvents sends the events of d to the channels of Watch. lines
+// is the result of lineIndex for the lines that were saved.
+func sendChangeEvents(watchers []*watcher, d Diff, lines map[string]int) {
+	line := func(host string) int {
+		if i, ok := lines[lowerASCII(host)]; ok {
+			return i
+		}
+		return -1
+	}
+	events := make([]ChangeEvent, 0, len(d.Removed)+len(d.Added)+len(d.Changed))
+	for _, e := range d.Removed {
+		events = append(events, ChangeEvent{Kind: OpDelete, Host: e.Host, OldIP: e.IP, Line: -1})
+	}
+	for _, e := range d.Added {
+		events = append(events, ChangeEvent{Kind: OpAdd, Host: e.Host, NewIP: e.IP, Line: line(e.Host)})
+	}
+	for _, c := range d.Changed {
+		events = append(events, ChangeEvent{Kind: OpUpdate, Host: c.Host, OldIP: c.OldIP, NewIP: c.NewIP, Line: line(c.Host)})
+	}
+
+	for _, w := range watchers {
+		w.send(events)
+	}
+}
+
+// lineIndex returns the index in h.Lines of the first active entry of every
+// host, keyed by the lowercase host.
+func (h *HostsEdit) lineIndex() map[string]int {
+	index := make(map[string]int)
+	for i, line := range h.Lines {
+		if !isActive(line) {
+			continue
+		}
+		for _, host := range line.hosts {
+			key := lowerASCII(host)
+			if _, ok := index[key]; !ok {
+				index[key] = i
+			}
+		}
+	}
+	return index
+}
+
+// send delivers events to the channel of w, dropping the oldest events
+// when it is full.
+func (w *watcher) send(events []ChangeEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	for _, e := range events {
+		for {
+			select {
+			case w.ch <- e:
+			default:
+				// 缓冲区已满，丢弃最旧的事件
+				select {
+				case <-w.ch:
+				default:
+				}
+				continue
+			}
+			break
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// 测试Watch方法
+func TestWatch(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n10.0.0.1 a.test b.test\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	events, cancel := hostsEdit.Watch()
+	defer cancel()
+
+	hostsEdit.Edit("new.test", "10.0.0.2")
+	hostsEdit.Delete("localhost")
+	// 批量操作每个主机一个事件
+	hostsEdit.ReplaceIP("10.0.0.1", "10.0.0.3")
+
+	want := []ChangeEvent{
+		{Kind: OpAdd, Host: "new.test", NewIP: "10.0.0.2", Line: 0},
+		{Kind: OpDelete, Host: "localhost", OldIP: "127.0.0.1", Line: -1},
+		{Kind: OpUpdate, Host: "a.test", OldIP: "10.0.0.1", NewIP: "10.0.0.3", Line: 1},
+		{Kind: OpUpdate, Host: "b.test", OldIP: "10.0.0.1", NewIP: "10.0.0.3", Line: 1},
+	}
+	var got []ChangeEvent
+	for len(events) > 0 {
+		got = append(got, <-events)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Watch() events = %+v; want %+v", got, want)
+	}
+}
+
+// 测试Watch通道满时丢弃最旧的事件
+func TestWatchDropOldest(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false)
+	events, cancel := hostsEdit.Watch()
+	for i := 0; i < watchBuffer+6; i++ {
+		hostsEdit.Edit(fmt.Sprintf("h%d.test", i), "10.0.0.1")
+	}
+	if len(events) != watchBuffer {
+		t.Fatalf("len(events) = %d; want %d", len(events), watchBuffer)
+	}
+	if e := <-events; e.Host != "h6.test" {
+		t.Errorf("oldest event = %+v; want h6.test", e)
+	}
+
+	cancel()
+	cancel()
+	// 取消后保存不再发送事件
+	hostsEdit.Edit("after.test", "10.0.0.1")
+	n := 0
+	for range events {
+		n++
+	}
+	if n != watchBuffer-1 {
+		t.Errorf("received %d events after cancel; want %d", n, watchBuffer-1)
+	}
+}
@@ -0,0 +1,130 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotWSL is returned by WindowsHostsPathFromWSL outside of WSL.
+var ErrNotWSL = errors.New("not running in WSL")
+
+// Files read to detect WSL and its mount settings, replaced in tests.
+var (
+	procVersionPath = "/proc/version"
+	wslConfPath     = "/etc/wsl.conf"
+)
+
+// windowsHostsRel is the location of the hosts file on the Windows system
+// drive.
+var windowsHostsRel = filepath.Join("Windows", "System32", "drivers", "etc", "hosts")
+
+// WindowsHostsPathFromWSL returns the path of the Windows hosts file as seen
+// from WSL, such as /mnt/c/Windows/System32/drivers/etc/hosts. WSL is
+// detected with the WSL_DISTRO_NAME environment variable or /proc/version.
+// The drives are looked up under the automount root set in /etc/wsl.conf,
+// /mnt/ by default, trying drive C first. It returns ErrNotWSL outside of
+// WSL.
+func WindowsHostsPathFromWSL() (string, error) {
+	if !isWSL() {
+		return "", ErrNotWSL
+	}
+
+	root := wslMountRoot()
+	path := filepath.Join(root, "c", windowsHostsRel)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	// 系统盘不是C盘时查找其他盘符
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		if len(entry.Name()) != 1 || !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(root, entry.Name(), windowsHostsRel)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("windows hosts file not found under %s", root)
+}
+
+// isWSL reports whether the program runs in WSL.
+func isWSL() bool {
+	if os.Getenv("WSL_DISTRO_NAME") != "" {
+		return true
+	}
+	version, err := os.ReadFile(procVersionPath)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(string(version)), "microsoft")
+}
+
+// wslMountRoot returns the directory the Windows drives are mounted under,
+// from the root setting of the automount section of wsl.conf.
+func wslMountRoot() string {
+	root := "/mnt/"
+	file, err := os.Open(wslConfPath)
+	if err != nil {
+		return root
+	}
+	defer file.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";") {
+			continue
+		}
+		if strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]") {
+			section = strings.ToLower(strings.TrimSpace(text[1 : len(text)-1]))
+			continue
+		}
+		key, value, ok := strings.Cut(text, "=")
+		if !ok || section != "automount" || strings.ToLower(strings.TrimSpace(key)) != "root" {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if value != "" {
+			root = value
+		}
+	}
+	return root
+}
+
+// WithWindowsHostsFromWSL is for editing the Windows hosts file from WSL.
+// It makes NewDefault use the path returned by WindowsHostsPathFromWSL when
+// running in WSL and no environment variable overrides the path, writes
+// CRLF line endings as Windows expects, and explains how to get write
+// access when saving fails because the file is read-only from WSL.
+func WithWindowsHostsFromWSL() Option {
+	return func(h *HostsEdit) {
+		h.wsl = true
+		h.eol = "\r\n"
+	}
+}
@@ -0,0 +1,103 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setWSLFiles 替换用于检测WSL的文件
+func setWSLFiles(t *testing.T, version, conf string) {
+	dir := t.TempDir()
+	oldVersion, oldConf := procVersionPath, wslConfPath
+	procVersionPath = filepath.Join(dir, "version")
+	wslConfPath = filepath.Join(dir, "wsl.conf")
+	t.Cleanup(func() {
+		procVersionPath, wslConfPath = oldVersion, oldConf
+	})
+	os.WriteFile(procVersionPath, []byte(version), 0644)
+	if conf != "" {
+		os.WriteFile(wslConfPath, []byte(conf), 0644)
+	}
+	t.Setenv("WSL_DISTRO_NAME", "")
+}
+
+// 测试WindowsHostsPathFromWSL函数
+func TestWindowsHostsPathFromWSL(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "drives")
+	hostsPath := filepath.Join(root, "d", windowsHostsRel)
+	os.MkdirAll(filepath.Dir(hostsPath), 0755)
+	os.WriteFile(hostsPath, []byte("127.0.0.1 localhost\r\n"), 0644)
+
+	setWSLFiles(t, "Linux version 5.15.0-microsoft-standard-WSL2", "[boot]\nsystemd=true\n[automount]\nenabled = true\nroot = \""+root+"/\"\n")
+	path, err := WindowsHostsPathFromWSL()
+	if err != nil || path != hostsPath {
+		t.Errorf("WindowsHostsPathFromWSL() = %q, %v; want %q", path, err, hostsPath)
+	}
+
+	setWSLFiles(t, "Linux version 6.1.0-generic", "")
+	if _, err := WindowsHostsPathFromWSL(); !errors.Is(err, ErrNotWSL) {
+		t.Errorf("WindowsHostsPathFromWSL() outside WSL error = %v; want %v", err, ErrNotWSL)
+	}
+	t.Setenv("WSL_DISTRO_NAME", "Ubuntu")
+	if _, err := WindowsHostsPathFromWSL(); errors.Is(err, ErrNotWSL) {
+		t.Errorf("WindowsHostsPathFromWSL() with WSL_DISTRO_NAME set error = %v", err)
+	}
+}
+
+// permissionFS 模拟在WSL中没有写权限的文件系统
+type permissionFS struct {
+	osFileSystem
+}
+
+func (permissionFS) Create(name string) (*os.File, error) {
+	return nil, &os.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+}
+
+func (permissionFS) CreateTemp(dir, pattern string) (*os.File, error) {
+	return nil, &os.PathError{Op: "createtemp", Path: dir, Err: fs.ErrPermission}
+}
+
+// 测试WithWindowsHostsFromWSL选项
+func TestWithWindowsHostsFromWSL(t *testing.T) {
+	filePath, err := createTestHostsFile("127.0.0.1 localhost\r\n")
+	if err != nil {
+		t.Fatalf("Failed to create test hosts file: %v", err)
+	}
+	defer os.Remove(filePath)
+
+	hostsEdit, _ := New(filePath, false, WithWindowsHostsFromWSL())
+	err = hostsEdit.Edit("myapp.local", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Edit(myapp.local, 10.0.0.1) failed with error: %v", err)
+	}
+	contentBytes, _ := os.ReadFile(filePath)
+	if string(contentBytes) != "10.0.0.1 myapp.local\r\n127.0.0.1 localhost\r\n" {
+		t.Errorf("saved content = %q; want CRLF line endings", contentBytes)
+	}
+
+	hostsEdit.fs = permissionFS{}
+	err = hostsEdit.Edit("other.local", "10.0.0.2")
+	if !errors.Is(err, fs.ErrPermission) || !strings.Contains(err.Error(), "administrator") {
+		t.Errorf("Edit() on a read-only file error = %v; want a permission error with a hint", err)
+	}
+}
@@ -0,0 +1,180 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLEntry is one element of the YAML document read by ImportYAML and
+// written by ExportYAML. The document is a list of entries:
+//
+//	# hosts.yaml
+//	- ip: 127.0.0.1
+//	  hosts: [localhost, myapp.local]
+//	  comment: local development
+//	  inline_comment: added by myapp
+//	- comment: a comment block with no entry after it
+//
+// Comment holds the comment lines directly above the entry, joined by "\n",
+// and InlineComment the comment after the hosts on the line of the entry.
+// An entry with only a comment represents comment lines that are not
+// followed by any entry.
+//
+// Export and Import use the same entries for FormatJSON and FormatCSV.
+type YAMLEntry struct {
+	IP      string   `yaml:"ip,omitempty" json:"ip,omitempty"`
+	Hosts   []string `yaml:"hosts,omitempty,flow" json:"hosts,omitempty"`
+	Comment string   `yaml:"comment,omitempty" json:"comment,omitempty"`
+	// InlineComment is the Line.InlineComment of the entry.
+	InlineComment string `yaml:"inline_comment,omitempty" json:"inline_comment,omitempty"`
+}
+
+// ExportYAML writes the hosts file to w in the YAMLEntry list format.
+// Lines that are neither comments nor valid entries are not exported.
+func (h *HostsEdit) ExportYAML(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	err := enc.Encode(h.entries())
+	if err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// entries returns the lines as a YAMLEntry list. Lines that are neither
+// comments nor valid entries are left out.
+func (h *HostsEdit) entries() []YAMLEntry {
+	var entries []YAMLEntry
+	var comments []string
+	for _, line := range h.Lines {
+		if line.IsComment {
+			comments = append(comments, commentText(line))
+			continue
+		}
+		if line.UndefinedRowsRawStr != "" || line.IP == "" {
+			continue
+		}
+		entries = append(entries, YAMLEntry{
+			IP:            line.IP,
+			Hosts:         line.Hosts(),
+			Comment:       strings.Join(comments, "\n"),
+			InlineComment: line.InlineComment,
+		})
+		comments = nil
+	}
+	if len(comments) > 0 {
+		entries = append(entries, YAMLEntry{Comment: strings.Join(comments, "\n")})
+	}
+	return entries
+}
+
+// ImportYAML replaces the content of the hosts file with the entries read
+// from r and saves it. If the instance was created with isParse, unknown
+// fields in the YAML document are an error and the result must pass the
+// same checks as New; otherwise unknown fields are ignored. Hosts may only
+// hold the characters of host names and cannot start with "-"; an entry
+// with any other host is an error, and nothing is imported.
+func (h *HostsEdit) ImportYAML(r io.Reader) error {
+	if h.readOnly {
+		return ErrReadOnly
+	}
+	var entries []YAMLEntry
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(h.isParse)
+	err := dec.Decode(&entries)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return h.importEntries(entries, operation{name: "ImportYAML"})
+}
+
+// importEntries replaces the lines with entries, checks them as ImportYAML
+// describes, and saves the file.
+func (h *HostsEdit) importEntries(entries []YAMLEntry, op operation) error {
+	var b strings.Builder
+	for i, entry := range entries {
+		if entry.Comment != "" {
+			for _, c := range strings.Split(entry.Comment, "\n") {
+				fmt.Fprintln(&b, "#", c)
+			}
+		}
+		if entry.IP == "" && len(entry.Hosts) == 0 && entry.InlineComment == "" {
+			continue
+		}
+		if net.ParseIP(entry.IP) == nil {
+			return fmt.Errorf("entry %d: invalid ip %q", i, entry.IP)
+		}
+		if len(entry.Hosts) == 0 {
+			return fmt.Errorf("entry %d: no hosts", i)
+		}
+		hosts := make([]string, len(entry.Hosts))
+		for j, host := range entry.Hosts {
+			// 和解析时相同的字符检查，另外拒绝以"-"开头的主机
+			if host == "" || !isHostToken(host) || host[0] == '-' {
+				return fmt.Errorf("entry %d: invalid host %q", i, host)
+			}
+			hosts[j] = host
+			if h.lowercaseHosts {
+				hosts[j] = lowerASCII(host)
+			}
+		}
+		if strings.ContainsAny(entry.InlineComment, "\r\n") {
+			return fmt.Errorf("entry %d: inline comment cannot contain line breaks", i)
+		}
+		if c := strings.TrimSpace(entry.InlineComment); c != "" {
+			fmt.Fprintln(&b, entry.IP, strings.Join(hosts, " "), "#", c)
+		} else {
+			fmt.Fprintln(&b, entry.IP, strings.Join(hosts, " "))
+		}
+	}
+
+	lines, err := parseLines(strings.NewReader(b.String()), false)
+	if err != nil {
+		return err
+	}
+	if h.isParse {
+		err = parse(lines)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, line := range lines {
+		line.dirty = true
+	}
+	h.Lines = lines
+	return h.saveToFile(op)
+}
+
+// commentText returns the text of a comment line without the leading "#".
+func commentText(line *Line) string {
+	if line.UndefinedRowsRawStr != "" || line.IP == "" {
+		return line.UndefinedRowsRawStr
+	}
+	text := line.IP + " " + strings.Join(line.Hosts(), " ")
+	if line.InlineComment != "" {
+		text += " # " + line.InlineComment
+	}
+	return text
+}
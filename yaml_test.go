@@ -0,0 +1,126 @@
+// host file edit library by Golang.
+// Copyright (C) 2024 CanQi Jin
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package hostedit_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	hostedit "github.com/Deng-Xian-Sheng/go-hosts-edit-library"
+	"github.com/Deng-Xian-Sheng/go-hosts-edit-library/hostedittest"
+)
+
+// 测试ExportYAML和ImportYAML的往返
+func TestYAMLRoundTrip(t *testing.T) {
+	hostsContent := `
+# loopback
+127.0.0.1 localhost myapp.local
+::1 ipv6host
+# trailing comment
+`
+	filePath := hostedittest.WriteTempHosts(t, hostsContent)
+
+	hostsEdit, _ := hostedit.New(filePath, false)
+
+	var buf bytes.Buffer
+	err := hostsEdit.ExportYAML(&buf)
+	if err != nil {
+		t.Fatalf("ExportYAML() error = %v", err)
+	}
+
+	want := `- ip: 127.0.0.1
+  hosts: [localhost, myapp.local]
+  comment: loopback
+- ip: ::1
+  hosts: [ipv6host]
+- comment: trailing comment
+`
+	if buf.String() != want {
+		t.Errorf("ExportYAML() differs (-want +got):\n%s", hostedittest.Diff(want, buf.String()))
+	}
+
+	err = hostsEdit.ImportYAML(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatalf("ImportYAML() error = %v", err)
+	}
+
+	updatedHostsEdit, _ := hostedit.New(filePath, false)
+	ip, exists := updatedHostsEdit.Get("myapp.local")
+	if !exists || ip != "127.0.0.1" {
+		t.Errorf("Get(myapp.local) = %v, %v; want %v, %v", ip, exists, "127.0.0.1", true)
+	}
+	if len(updatedHostsEdit.Lines) != 4 {
+		t.Errorf("Expected 4 lines, got %d", len(updatedHostsEdit.Lines))
+	}
+}
+
+// 测试ExportYAML和ImportYAML保留行内注释
+func TestYAMLInlineComment(t *testing.T) {
+	hostsContent := "10.0.0.1 a # note\n"
+	filePath := hostedittest.WriteTempHosts(t, hostsContent)
+
+	hostsEdit, _ := hostedit.New(filePath, false)
+	var buf bytes.Buffer
+	err := hostsEdit.ExportYAML(&buf)
+	if err != nil {
+		t.Fatalf("ExportYAML() error = %v", err)
+	}
+	want := "- ip: 10.0.0.1\n  hosts: [a]\n  inline_comment: note\n"
+	if buf.String() != want {
+		t.Errorf("ExportYAML() differs (-want +got):\n%s", hostedittest.Diff(want, buf.String()))
+	}
+
+	targetPath := hostedittest.WriteTempHosts(t, "127.0.0.1 localhost\n")
+	target, _ := hostedit.New(targetPath, true)
+	err = target.ImportYAML(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatalf("ImportYAML() error = %v", err)
+	}
+	hostedittest.AssertContent(t, targetPath, hostsContent)
+
+	doc := "- ip: 10.0.0.1\n  hosts: [a]\n  inline_comment: \"x\\ny\"\n"
+	if err := target.ImportYAML(strings.NewReader(doc)); err == nil {
+		t.Errorf("ImportYAML() with a line break in inline_comment should fail")
+	}
+}
+
+// 测试ImportYAML在严格模式下拒绝未知字段
+func TestImportYAMLUnknownField(t *testing.T) {
+	filePath := hostedittest.WriteTempHosts(t, "127.0.0.1 localhost\n")
+
+	doc := `- ip: 10.0.0.1
+  hosts: [api.test]
+  owner: me
+`
+	strict, _ := hostedit.New(filePath, true)
+	if err := strict.ImportYAML(strings.NewReader(doc)); err == nil {
+		t.Errorf("ImportYAML() with unknown field in strict mode should fail")
+	}
+
+	lenient, _ := hostedit.New(filePath, false)
+	if err := lenient.ImportYAML(strings.NewReader(doc)); err != nil {
+		t.Errorf("ImportYAML() with unknown field in lenient mode error = %v", err)
+	}
+	if !lenient.Exists("api.test") {
+		t.Errorf("ImportYAML() failed to add api.test")
+	}
+
+	if err := lenient.ImportYAML(strings.NewReader("- ip: nope\n  hosts: [x]\n")); err == nil {
+		t.Errorf("ImportYAML() with invalid ip should fail")
+	}
+}